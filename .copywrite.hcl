@@ -20,5 +20,8 @@ project {
 
     # GoReleaser tooling configuration
     ".goreleaser.yml",
+
+    # Code generated from api/openapi.yaml
+    "internal/api/*.gen.go",
   ]
 }
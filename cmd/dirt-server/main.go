@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command dirt-server runs the DirtCloud API stand-in from internal/server. State is
+// kept in memory unless -data-dir is set.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+	"github.com/terraform-provider-dirt/internal/server"
+)
+
+func main() {
+	var (
+		addr          string
+		cpu           int
+		memoryMB      int
+		deterministic bool
+		seed          int
+		start         string
+		fixtures      string
+		dataDir       string
+		tokens        string
+	)
+
+	flag.StringVar(&addr, "listen", "localhost:8080", "address to listen on")
+	flag.IntVar(&cpu, "quota-cpu", 0, "per-project CPU quota (0 means unlimited)")
+	flag.IntVar(&memoryMB, "quota-memory-mb", 0, "per-project memory quota in MB (0 means unlimited)")
+	flag.BoolVar(&deterministic, "deterministic", false, "use sequential IDs and a fake clock for reproducible runs")
+	flag.IntVar(&seed, "seed", 0, "offset for sequential IDs in deterministic mode")
+	flag.StringVar(&start, "start", "", "initial fake clock time (RFC 3339) in deterministic mode")
+	flag.StringVar(&fixtures, "fixtures", "", "JSON fixtures file to preload at startup")
+	flag.StringVar(&dataDir, "data-dir", "", "directory to persist state in (default: in memory only)")
+	flag.StringVar(&tokens, "tokens", "", "JSON file of API tokens and roles; when unset, requests are not authenticated")
+	flag.Parse()
+
+	opts := server.Options{}
+	if deterministic {
+		var startTime time.Time
+		if start != "" {
+			var err error
+			if startTime, err = time.Parse(time.RFC3339, start); err != nil {
+				log.Fatalf("invalid -start: %s", err)
+			}
+		}
+		opts = server.Deterministic(seed, startTime)
+	}
+	opts.DefaultQuota = client.ResourceAmounts{CPU: cpu, MemoryMB: memoryMB}
+
+	if tokens != "" {
+		config, err := server.LoadTokensFile(tokens)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Tokens = config
+	}
+
+	var store server.Store = server.NewMemoryStore()
+	if dataDir != "" {
+		fileStore, err := server.OpenFileStore(dataDir)
+		if err != nil {
+			log.Fatalf("opening data directory: %s", err)
+		}
+		store = fileStore
+	}
+
+	srv, err := server.NewWithStore(opts, store)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fixtures only seed a fresh server; persisted state from earlier runs wins.
+	if fixtures != "" && empty(srv.Snapshot()) {
+		f, err := server.LoadFixturesFile(fixtures)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := srv.ApplyFixtures(f); err != nil {
+			log.Fatalf("applying fixtures: %s", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: addr, Handler: srv}
+	// Shutdown waits for open requests, so end metadata watch streams first
+	httpServer.RegisterOnShutdown(srv.StopWatches)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("DirtCloud stand-in server running on http://%s/v1", addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+
+	if err := srv.Close(); err != nil {
+		log.Fatalf("closing store: %s", err)
+	}
+}
+
+// empty reports whether snapshot contains no resources.
+func empty(snapshot client.Snapshot) bool {
+	return len(snapshot.Projects) == 0 && len(snapshot.Instances) == 0 && len(snapshot.Metadata) == 0 &&
+		len(snapshot.Buckets) == 0 && len(snapshot.Objects) == 0
+}
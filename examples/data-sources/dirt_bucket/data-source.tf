@@ -0,0 +1,21 @@
+# Look up a bucket owned by another configuration by name
+data "dirt_bucket" "shared_assets" {
+  name = "shared-assets"
+}
+
+# ... or by ID
+data "dirt_bucket" "by_id" {
+  id = "bucket-id-12345"
+}
+
+output "shared_assets_bucket_id" {
+  description = "ID of the shared assets bucket"
+  value       = data.dirt_bucket.shared_assets.id
+}
+
+# Example using the data source to store an object in an existing bucket
+resource "dirt_object" "robots" {
+  bucket_id      = data.dirt_bucket.shared_assets.id
+  path           = "robots.txt"
+  content_base64 = base64encode("User-agent: *\nDisallow:\n")
+}
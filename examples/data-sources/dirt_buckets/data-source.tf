@@ -0,0 +1,19 @@
+# All buckets whose name starts with "team-a-"
+data "dirt_buckets" "team_a" {
+  name_prefix = "team-a-"
+}
+
+# Buckets matching a regular expression
+data "dirt_buckets" "logs" {
+  name_regex = "-logs$"
+}
+
+output "team_a_bucket_ids" {
+  description = "IDs of team A's buckets"
+  value       = data.dirt_buckets.team_a.ids
+}
+
+output "empty_log_buckets" {
+  description = "Names of log buckets without objects"
+  value       = [for b in data.dirt_buckets.logs.buckets : b.name if b.object_count == 0]
+}
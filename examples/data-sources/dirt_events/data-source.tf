@@ -0,0 +1,18 @@
+# All changes made to a single instance
+data "dirt_events" "web_server" {
+  resource_id = "instance-id-67890"
+}
+
+# Instance deletions since October 1st
+data "dirt_events" "deleted_instances" {
+  resource_type = "instance"
+  action        = "delete"
+  since         = "2025-10-01T00:00:00Z"
+}
+
+output "web_server_changes" {
+  description = "Who changed the instance, and through which client"
+  value = [
+    for e in data.dirt_events.web_server.events : "${e.timestamp} ${e.actor} (${e.source}) ${e.action}"
+  ]
+}
@@ -2,6 +2,17 @@ data "dirt_instance" "existing" {
   id = "instance-id-67890"
 }
 
+# Look up an instance by name; project_id narrows the lookup, as instance
+# names are only unique within a project
+data "dirt_instance" "payments_api" {
+  project_id = data.dirt_project.payments.id
+  name       = "api"
+}
+
+data "dirt_project" "payments" {
+  name = "payments"
+}
+
 output "instance_details" {
   description = "Details of the existing instance"
   value = {
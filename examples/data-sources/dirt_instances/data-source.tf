@@ -0,0 +1,27 @@
+data "dirt_project" "production" {
+  id = "project-id-12345"
+}
+
+# Large stopped instances in the production project
+data "dirt_instances" "idle_large" {
+  project_id    = data.dirt_project.production.id
+  status        = "stopped"
+  cpu_min       = 8
+  memory_mb_min = 16384
+}
+
+# Instances still running an old image
+data "dirt_instances" "outdated" {
+  name_regex = "^web-"
+  image      = "ubuntu:20.04"
+}
+
+output "idle_large_instance_ids" {
+  description = "IDs of large instances that are stopped"
+  value       = data.dirt_instances.idle_large.ids
+}
+
+output "outdated_instances" {
+  description = "Name and project of every instance still on ubuntu:20.04"
+  value       = { for i in data.dirt_instances.outdated.instances : i.name => i.project_id }
+}
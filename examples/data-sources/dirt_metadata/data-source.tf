@@ -6,6 +6,33 @@ data "dirt_metadata" "feature_flag" {
   path = "app/features/new_ui_enabled"
 }
 
+# JSON values are also available decoded, without jsondecode()
+data "dirt_metadata" "rollout" {
+  path = "features/new_ui_rollout"
+}
+
+output "rollout_regions" {
+  description = "Regions the new UI is rolled out to"
+  value       = data.dirt_metadata.rollout.value_json.regions
+}
+
+# Read the value as of a past version
+data "dirt_metadata" "feature_flag_v1" {
+  path    = "app/features/new_ui_enabled"
+  version = 1
+}
+
+# Entries the server flags as secret are only returned in sensitive_value
+data "dirt_metadata" "api_key" {
+  path = "app/config/api_key"
+}
+
+output "api_key" {
+  description = "API key from metadata"
+  value       = data.dirt_metadata.api_key.sensitive ? data.dirt_metadata.api_key.sensitive_value : data.dirt_metadata.api_key.value
+  sensitive   = true
+}
+
 output "database_url" {
   description = "Database URL from metadata"
   value       = data.dirt_metadata.app_config.value
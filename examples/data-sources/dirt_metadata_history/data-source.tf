@@ -0,0 +1,25 @@
+data "dirt_metadata_history" "new_ui" {
+  path = "app/features/new_ui_enabled"
+}
+
+output "new_ui_history" {
+  description = "Every value the new UI flag has had, oldest first"
+  value = [
+    for v in data.dirt_metadata_history.new_ui.versions : {
+      version    = v.version
+      value      = v.value
+      changed_at = v.created_at
+    }
+  ]
+}
+
+# Roll the flag back to the value it had before the last change
+data "dirt_metadata" "new_ui_previous" {
+  path    = "app/features/new_ui_enabled"
+  version = data.dirt_metadata_history.new_ui.current_version - 1
+}
+
+resource "dirt_metadata" "new_ui" {
+  path  = "app/features/new_ui_enabled"
+  value = data.dirt_metadata.new_ui_previous.value
+}
@@ -0,0 +1,35 @@
+# Read the whole app/config/ subtree, e.g. app/config/region and app/config/db/host
+data "dirt_metadata_prefix" "app_config" {
+  prefix = "app/config/"
+}
+
+resource "dirt_instance" "db_client" {
+  project_id = "project-id-12345"
+  name       = "db-client-${data.dirt_metadata_prefix.app_config.values["region"]}"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "postgres:16"
+}
+
+output "db_endpoint" {
+  description = "Database endpoint assembled from app/config/db/*"
+  value       = "${data.dirt_metadata_prefix.app_config.nested.db.host}:${data.dirt_metadata_prefix.app_config.nested.db.port}"
+}
+
+# Only the entries directly under app/config/, keyed by their full path
+data "dirt_metadata_prefix" "top_level" {
+  prefix       = "app/config/"
+  recursive    = false
+  strip_prefix = false
+}
+
+# Secrets are redacted in plan output and only exposed through sensitive_values
+data "dirt_metadata_prefix" "secrets" {
+  prefix    = "app/secrets/"
+  sensitive = true
+}
+
+output "secret_names" {
+  description = "Names of the stored secrets"
+  value       = nonsensitive(keys(data.dirt_metadata_prefix.secrets.sensitive_values))
+}
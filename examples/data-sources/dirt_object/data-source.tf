@@ -0,0 +1,27 @@
+data "dirt_bucket" "config" {
+  name = "shared-config"
+}
+
+# Read a configuration file stored in a bucket by path
+data "dirt_object" "app_config" {
+  bucket_id = data.dirt_bucket.config.id
+  path      = "apps/web/config.json"
+}
+
+# Feed the decoded content into other resources
+locals {
+  app_config = jsondecode(data.dirt_object.app_config.content)
+}
+
+resource "dirt_instance" "web" {
+  project_id = local.app_config.project_id
+  name       = "web"
+  cpu        = local.app_config.cpu
+  memory_mb  = local.app_config.memory_mb
+  image      = local.app_config.image
+}
+
+output "app_config_sha256" {
+  description = "Hash of the configuration file, e.g. to trigger redeployments"
+  value       = data.dirt_object.app_config.content_sha256
+}
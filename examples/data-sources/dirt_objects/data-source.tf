@@ -0,0 +1,25 @@
+data "dirt_bucket" "site" {
+  name = "static-site"
+}
+
+# List the files and "directories" directly under site/
+data "dirt_objects" "site_root" {
+  bucket_id = data.dirt_bucket.site.id
+  prefix    = "site/"
+  delimiter = "/"
+}
+
+output "site_root_files" {
+  description = "Paths of the objects directly under site/"
+  value       = data.dirt_objects.site_root.objects[*].path
+}
+
+output "site_directories" {
+  description = "Directories under site/, such as site/css/"
+  value       = data.dirt_objects.site_root.common_prefixes
+}
+
+output "site_total_bytes" {
+  description = "Total size of the objects directly under site/"
+  value       = sum(concat([0], data.dirt_objects.site_root.objects[*].size))
+}
@@ -2,6 +2,11 @@ data "dirt_project" "existing" {
   id = "project-id-12345"
 }
 
+# Look up a project by name instead of ID
+data "dirt_project" "payments" {
+  name = "payments"
+}
+
 output "project_name" {
   description = "Name of the existing project"
   value       = data.dirt_project.existing.name
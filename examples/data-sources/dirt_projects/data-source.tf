@@ -0,0 +1,26 @@
+# All projects whose name starts with "team-"
+data "dirt_projects" "teams" {
+  name_regex = "^team-"
+}
+
+# Projects created during the last quarter
+data "dirt_projects" "recent" {
+  created_since = "2025-07-01T00:00:00Z"
+  created_until = "2025-09-30T23:59:59Z"
+}
+
+# One instance per team project
+resource "dirt_instance" "monitoring" {
+  for_each = toset(data.dirt_projects.teams.ids)
+
+  project_id = each.key
+  name       = "monitoring"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "prom/node-exporter:v1.8.2"
+}
+
+output "team_project_names" {
+  description = "Names of the team projects"
+  value       = data.dirt_projects.teams.projects[*].name
+}
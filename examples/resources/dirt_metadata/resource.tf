@@ -13,6 +13,45 @@ resource "dirt_metadata" "version" {
   value = "1.2.3"
 }
 
+# Structured values are stored as JSON; reformatting the stored document is not drift
+resource "dirt_metadata" "rollout" {
+  path = "features/new_ui_rollout"
+  value_json = jsonencode({
+    enabled = true
+    percent = 25
+    regions = ["eu-west", "us-east"]
+  })
+}
+
+# Secrets are set with the write-only value_wo (Terraform 1.11 or later), so they are
+# never stored in the plan or state. Changes on either side are detected through
+# value_sha256; bump value_wo_version to send the value again.
+variable "api_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "dirt_metadata" "api_key" {
+  path             = "app/config/api_key"
+  value_wo         = var.api_key
+  value_wo_version = 1
+  sensitive        = true
+}
+
+# Entries can expire: ttl restarts on every update, expires_at is a fixed timestamp.
+# Expired entries are removed from state with a warning and created again on apply.
+resource "dirt_metadata" "maintenance_banner" {
+  path  = "app/banners/maintenance"
+  value = "Scheduled maintenance on Saturday"
+  ttl   = "72h"
+}
+
+resource "dirt_metadata" "promo_code" {
+  path       = "app/promotions/code"
+  value      = "WINTER25"
+  expires_at = "2026-03-01T00:00:00Z"
+}
+
 # Example using dynamic values from other resources
 resource "dirt_metadata" "instance_info" {
   path  = "instances/${dirt_instance.web_server.id}/description"
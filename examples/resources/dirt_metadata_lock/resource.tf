@@ -0,0 +1,35 @@
+variable "ci_job_id" {
+  type        = string
+  description = "ID of the CI job running this configuration"
+}
+
+# Only one pipeline at a time may deploy to the shared environment. The lock is
+# acquired before the instance is changed and released when the lock is destroyed,
+# e.g. with `terraform destroy -target=dirt_metadata_lock.deploy` at the end of the job.
+resource "dirt_metadata_lock" "deploy" {
+  path          = "locks/staging/deploy"
+  holder        = var.ci_job_id
+  wait          = "15m"
+  poll_interval = "10s"
+
+  # Released automatically if the job dies without releasing it
+  ttl = "2h"
+}
+
+resource "dirt_project" "staging" {
+  name = "staging"
+}
+
+resource "dirt_instance" "app" {
+  project_id = dirt_project.staging.id
+  name       = "app"
+  cpu        = 2
+  memory_mb  = 2048
+
+  depends_on = [dirt_metadata_lock.deploy]
+}
+
+output "lock_acquired_at" {
+  description = "When this job acquired the deploy lock"
+  value       = dirt_metadata_lock.deploy.acquired_at
+}
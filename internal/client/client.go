@@ -7,34 +7,75 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-
+	"strconv"
 	"time"
+
+	"github.com/terraform-provider-dirt/internal/api"
 )
 
 // ErrorResponse represents an error response from the server.
-type ErrorResponse struct {
-	Error   string                 `json:"error"`
-	Message string                 `json:"message"`
-	Details map[string]interface{} `json:"details,omitempty"`
+type ErrorResponse = api.ErrorResponse
+
+// APIError is an error response returned by the DirtCloud API.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// ErrQuotaNotFound is returned by GetProjectQuota when the server has no quota for the
+// project, either because the project is not visible or because it has no quota API.
+var ErrQuotaNotFound = errors.New("project quota not found")
+
+// ConflictError is returned by conditional metadata writes whose condition does not
+// hold: the path is already taken, or the value or version is not the expected one.
+// It wraps the *APIError answered by the server.
+type ConflictError struct {
+	Err *APIError
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// asConflict turns 409 and 412 responses in err into a *ConflictError.
+func asConflict(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusConflict || apiErr.StatusCode == http.StatusPreconditionFailed) {
+		return &ConflictError{Err: apiErr}
+	}
+	return err
 }
 
 // parseErrorResponse parses error response body and returns a formatted error.
 func parseErrorResponse(resp *http.Response) error {
 	var errResp ErrorResponse
 	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-		return fmt.Errorf("HTTP %d: %s (failed to parse error response)", resp.StatusCode, resp.Status)
+		return &APIError{StatusCode: resp.StatusCode, Message: resp.Status + " (failed to parse error response)"}
 	}
-	
+
 	if errResp.Message != "" {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Message)
+		return &APIError{StatusCode: resp.StatusCode, Code: errResp.Error, Message: errResp.Message}
 	}
-	
-	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+
+	return &APIError{StatusCode: resp.StatusCode, Code: errResp.Error, Message: resp.Status}
 }
 
 // Client represents the DirtCloud API client.
@@ -42,6 +83,8 @@ type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Token      string
+	// UserAgent identifies the caller to the server, which records it as the source of audit events.
+	UserAgent string
 }
 
 // NewClient creates a new DirtCloud API client.
@@ -57,112 +100,74 @@ func NewClient(baseURL string) *Client {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		Token: token,
+		Token:     token,
+		UserAgent: "terraform-provider-dirt",
 	}
 }
 
 // Project represents a DirtCloud project.
-type Project struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Project = api.Project
 
 // CreateProjectRequest represents the request body for creating a project.
-type CreateProjectRequest struct {
-	Name string `json:"name"`
-}
+type CreateProjectRequest = api.CreateProjectRequest
 
 // UpdateProjectRequest represents the request body for updating a project.
-type UpdateProjectRequest struct {
-	Name string `json:"name"`
-}
+type UpdateProjectRequest = api.UpdateProjectRequest
+
+// ResourceAmounts holds a CPU and memory pair used for both quota limits and usage.
+type ResourceAmounts = api.ResourceAmounts
+
+// ProjectQuota represents the compute quota of a project along with its current usage.
+// A limit of zero means the dimension is unlimited.
+type ProjectQuota = api.ProjectQuota
 
 // Instance represents a DirtCloud instance.
-type Instance struct {
-	ID        string    `json:"id"`
-	ProjectID string    `json:"project_id"`
-	Name      string    `json:"name"`
-	CPU       int       `json:"cpu"`
-	MemoryMB  int       `json:"memory_mb"`
-	Image     string    `json:"image"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Instance = api.Instance
 
 // CreateInstanceRequest represents the request body for creating an instance.
-type CreateInstanceRequest struct {
-	ProjectID string `json:"project_id"`
-	Name      string `json:"name"`
-	CPU       int    `json:"cpu"`
-	MemoryMB  int    `json:"memory_mb"`
-	Image     string `json:"image"`
-	Status    string `json:"status,omitempty"`
-}
+type CreateInstanceRequest = api.CreateInstanceRequest
 
 // UpdateInstanceRequest represents the request body for updating an instance.
-type UpdateInstanceRequest struct {
-	Name     *string `json:"name,omitempty"`
-	CPU      *int    `json:"cpu,omitempty"`
-	MemoryMB *int    `json:"memory_mb,omitempty"`
-	Image    *string `json:"image,omitempty"`
-	Status   *string `json:"status,omitempty"`
-}
+type UpdateInstanceRequest = api.UpdateInstanceRequest
 
 // Metadata represents DirtCloud metadata.
-type Metadata struct {
-	ID        string    `json:"id"`
-	Path      string    `json:"path"`
-	Value     string    `json:"value"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Metadata = api.Metadata
 
 // Bucket represents a DirtCloud bucket.
-type Bucket struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Bucket = api.Bucket
 
 // CreateBucketRequest represents the request body for creating a bucket.
-type CreateBucketRequest struct {
-	Name string `json:"name"`
-}
+type CreateBucketRequest = api.CreateBucketRequest
 
 // UpdateBucketRequest represents the request body for updating a bucket.
-type UpdateBucketRequest struct {
-	Name string `json:"name"`
-}
+type UpdateBucketRequest = api.UpdateBucketRequest
 
 // Object represents a DirtCloud object stored in a bucket. Content is base64-encoded.
-type Object struct {
-	ID        string    `json:"id"`
-	BucketID  string    `json:"bucket_id"`
-	Path      string    `json:"path"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+type Object = api.Object
 
 // CreateObjectRequest represents the request body for creating an object.
-type CreateObjectRequest struct {
-	BucketID string `json:"bucket_id,omitempty"`
-	Path     string `json:"path"`
-	Content  string `json:"content"`
-}
+type CreateObjectRequest = api.CreateObjectRequest
 
 // UpdateObjectRequest represents the request body for updating an object.
-type UpdateObjectRequest struct {
-	Path    *string `json:"path,omitempty"`
-	Content *string `json:"content,omitempty"`
-}
+type UpdateObjectRequest = api.UpdateObjectRequest
 
 // doRequest performs an HTTP request with proper authentication.
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// newRequest creates a request to endpoint with the client's credentials and user agent.
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
 	fullURL := c.BaseURL + endpoint
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
@@ -173,16 +178,15 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body io
 		req.Header.Set("Authorization", "Bearer "+c.Token)
 	}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	return resp, nil
+	return req, nil
 }
 
 // Buckets API
@@ -214,7 +218,7 @@ func (c *Client) CreateBucket(ctx context.Context, req CreateBucketRequest) (*Bu
 
 // GetBucket retrieves a bucket by ID.
 func (c *Client) GetBucket(ctx context.Context, id string) (*Bucket, error) {
-	resp, err := c.doRequest(ctx, "GET", "/buckets/"+id, nil)
+	resp, err := c.doRequest(ctx, "GET", "/buckets/"+url.PathEscape(id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -236,6 +240,31 @@ func (c *Client) GetBucket(ctx context.Context, id string) (*Bucket, error) {
 	return &bucket, nil
 }
 
+// ListBuckets retrieves all buckets, optionally filtered by name.
+func (c *Client) ListBuckets(ctx context.Context, nameFilter string) ([]Bucket, error) {
+	endpoint := "/buckets"
+	if nameFilter != "" {
+		endpoint += "?name=" + url.QueryEscape(nameFilter)
+	}
+
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var buckets []Bucket
+	if err := json.NewDecoder(resp.Body).Decode(&buckets); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return buckets, nil
+}
+
 // UpdateBucket updates a bucket by ID.
 func (c *Client) UpdateBucket(ctx context.Context, id string, req UpdateBucketRequest) (*Bucket, error) {
 	body, err := json.Marshal(req)
@@ -243,7 +272,7 @@ func (c *Client) UpdateBucket(ctx context.Context, id string, req UpdateBucketRe
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "PATCH", "/buckets/"+id, bytes.NewReader(body))
+	resp, err := c.doRequest(ctx, "PATCH", "/buckets/"+url.PathEscape(id), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -267,7 +296,7 @@ func (c *Client) UpdateBucket(ctx context.Context, id string, req UpdateBucketRe
 
 // DeleteBucket deletes a bucket by ID.
 func (c *Client) DeleteBucket(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/buckets/"+id, nil)
+	resp, err := c.doRequest(ctx, "DELETE", "/buckets/"+url.PathEscape(id), nil)
 	if err != nil {
 		return err
 	}
@@ -426,7 +455,7 @@ func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (*
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var project Project
@@ -439,7 +468,7 @@ func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (*
 
 // GetProject retrieves a project by ID.
 func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
-	resp, err := c.doRequest(ctx, "GET", "/projects/"+id, nil)
+	resp, err := c.doRequest(ctx, "GET", "/projects/"+url.PathEscape(id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -450,7 +479,7 @@ func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var project Project
@@ -475,7 +504,7 @@ func (c *Client) ListProjects(ctx context.Context, nameFilter string) ([]Project
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var projects []Project
@@ -493,7 +522,7 @@ func (c *Client) UpdateProject(ctx context.Context, id string, req UpdateProject
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "PATCH", "/projects/"+id, bytes.NewReader(body))
+	resp, err := c.doRequest(ctx, "PATCH", "/projects/"+url.PathEscape(id), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -504,7 +533,7 @@ func (c *Client) UpdateProject(ctx context.Context, id string, req UpdateProject
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var project Project
@@ -517,7 +546,7 @@ func (c *Client) UpdateProject(ctx context.Context, id string, req UpdateProject
 
 // DeleteProject deletes a project.
 func (c *Client) DeleteProject(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/projects/"+id, nil)
+	resp, err := c.doRequest(ctx, "DELETE", "/projects/"+url.PathEscape(id), nil)
 	if err != nil {
 		return err
 	}
@@ -528,12 +557,37 @@ func (c *Client) DeleteProject(ctx context.Context, id string) error {
 	}
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return parseErrorResponse(resp)
 	}
 
 	return nil
 }
 
+// GetProjectQuota retrieves the quota limits and current usage of a project.
+// Servers without a quota API answer 404 or 501; both are reported as ErrQuotaNotFound.
+func (c *Client) GetProjectQuota(ctx context.Context, projectID string) (*ProjectQuota, error) {
+	resp, err := c.doRequest(ctx, "GET", "/projects/"+url.PathEscape(projectID)+"/quota", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrQuotaNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var quota ProjectQuota
+	if err := json.NewDecoder(resp.Body).Decode(&quota); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &quota, nil
+}
+
 // Instances API
 
 // CreateInstance creates a new instance.
@@ -550,7 +604,7 @@ func (c *Client) CreateInstance(ctx context.Context, req CreateInstanceRequest)
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var instance Instance
@@ -563,7 +617,7 @@ func (c *Client) CreateInstance(ctx context.Context, req CreateInstanceRequest)
 
 // GetInstance retrieves an instance by ID.
 func (c *Client) GetInstance(ctx context.Context, id string) (*Instance, error) {
-	resp, err := c.doRequest(ctx, "GET", "/instances/"+id, nil)
+	resp, err := c.doRequest(ctx, "GET", "/instances/"+url.PathEscape(id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -574,7 +628,7 @@ func (c *Client) GetInstance(ctx context.Context, id string) (*Instance, error)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var instance Instance
@@ -611,7 +665,7 @@ func (c *Client) ListInstances(ctx context.Context, projectID, nameFilter, statu
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var instances []Instance
@@ -629,7 +683,7 @@ func (c *Client) UpdateInstance(ctx context.Context, id string, req UpdateInstan
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "PATCH", "/instances/"+id, bytes.NewReader(body))
+	resp, err := c.doRequest(ctx, "PATCH", "/instances/"+url.PathEscape(id), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -653,7 +707,7 @@ func (c *Client) UpdateInstance(ctx context.Context, id string, req UpdateInstan
 
 // DeleteInstance deletes an instance.
 func (c *Client) DeleteInstance(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/instances/"+id, nil)
+	resp, err := c.doRequest(ctx, "DELETE", "/instances/"+url.PathEscape(id), nil)
 	if err != nil {
 		return err
 	}
@@ -664,7 +718,7 @@ func (c *Client) DeleteInstance(ctx context.Context, id string) error {
 	}
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return parseErrorResponse(resp)
 	}
 
 	return nil
@@ -673,16 +727,10 @@ func (c *Client) DeleteInstance(ctx context.Context, id string) error {
 // Metadata API
 
 // CreateMetadataRequest represents the request body for creating metadata.
-type CreateMetadataRequest struct {
-	Path  string `json:"path"`
-	Value string `json:"value"`
-}
+type CreateMetadataRequest = api.CreateMetadataRequest
 
 // UpdateMetadataRequest represents the request body for updating metadata.
-type UpdateMetadataRequest struct {
-	Path  *string `json:"path,omitempty"`
-	Value *string `json:"value,omitempty"`
-}
+type UpdateMetadataRequest = api.UpdateMetadataRequest
 
 // CreateMetadata creates new metadata.
 func (c *Client) CreateMetadata(ctx context.Context, req CreateMetadataRequest) (*Metadata, error) {
@@ -698,7 +746,7 @@ func (c *Client) CreateMetadata(ctx context.Context, req CreateMetadataRequest)
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var metadata Metadata
@@ -711,18 +759,18 @@ func (c *Client) CreateMetadata(ctx context.Context, req CreateMetadataRequest)
 
 // GetMetadata retrieves metadata by ID.
 func (c *Client) GetMetadata(ctx context.Context, id string) (*Metadata, error) {
-	resp, err := c.doRequest(ctx, "GET", "/metadata/"+id, nil)
+	resp, err := c.doRequest(ctx, "GET", "/metadata/"+url.PathEscape(id), nil)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("metadata not found")
+		return nil, metadataNotFound(resp)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var metadata Metadata
@@ -765,7 +813,7 @@ func (c *Client) ListMetadata(ctx context.Context, prefix string) ([]Metadata, e
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var metadata []Metadata
@@ -783,18 +831,18 @@ func (c *Client) UpdateMetadata(ctx context.Context, id string, req UpdateMetada
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "PATCH", "/metadata/"+id, bytes.NewReader(body))
+	resp, err := c.doRequest(ctx, "PATCH", "/metadata/"+url.PathEscape(id), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("metadata not found")
+		return nil, metadataNotFound(resp)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, parseErrorResponse(resp)
 	}
 
 	var metadata Metadata
@@ -807,7 +855,7 @@ func (c *Client) UpdateMetadata(ctx context.Context, id string, req UpdateMetada
 
 // DeleteMetadata deletes metadata by ID.
 func (c *Client) DeleteMetadata(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/metadata/"+id, nil)
+	resp, err := c.doRequest(ctx, "DELETE", "/metadata/"+url.PathEscape(id), nil)
 	if err != nil {
 		return err
 	}
@@ -818,8 +866,298 @@ func (c *Client) DeleteMetadata(ctx context.Context, id string) error {
 	}
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return parseErrorResponse(resp)
+	}
+
+	return nil
+}
+
+// metadataNotFound returns the error for a 404 response to a request for a single
+// metadata entry. Entries that have expired are reported as an *APIError with code
+// "expired", so callers can tell them apart from entries that never existed or were
+// deleted.
+func metadataNotFound(resp *http.Response) error {
+	err := parseErrorResponse(resp)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "expired" {
+		return apiErr
+	}
+	return fmt.Errorf("metadata not found")
+}
+
+// CreateMetadataIfAbsent creates metadata only if no other metadata has the path, e.g.
+// to acquire a lock. A taken path is reported as a *ConflictError.
+func (c *Client) CreateMetadataIfAbsent(ctx context.Context, req CreateMetadataRequest) (*Metadata, error) {
+	metadata, err := c.CreateMetadata(ctx, req)
+	return metadata, asConflict(err)
+}
+
+// CompareAndSwapMetadata updates metadata only if its current value is req.IfValue and
+// its version is req.IfVersion, whichever are set; at least one must be. A condition that
+// does not hold is reported as a *ConflictError, as is a path taken by other metadata.
+func (c *Client) CompareAndSwapMetadata(ctx context.Context, id string, req UpdateMetadataRequest) (*Metadata, error) {
+	if req.IfValue == nil && req.IfVersion == nil {
+		return nil, fmt.Errorf("compare-and-swap requires IfValue or IfVersion")
+	}
+	metadata, err := c.UpdateMetadata(ctx, id, req)
+	return metadata, asConflict(err)
+}
+
+// DeleteMetadataIfVersion deletes metadata only if it is at the given version, e.g. to
+// release a lock that has not been taken over. Another version is reported as a
+// *ConflictError.
+func (c *Client) DeleteMetadataIfVersion(ctx context.Context, id string, version int) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/metadata/"+url.PathEscape(id)+"?if_version="+strconv.Itoa(version), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("metadata not found")
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		return asConflict(parseErrorResponse(resp))
+	}
+
+	return nil
+}
+
+// MetadataVersion represents the state of metadata as of one of its versions.
+type MetadataVersion = api.MetadataVersion
+
+// ListMetadataVersions lists every version of metadata, oldest first.
+func (c *Client) ListMetadataVersions(ctx context.Context, id string) ([]MetadataVersion, error) {
+	resp, err := c.doRequest(ctx, "GET", "/metadata/"+url.PathEscape(id)+"/versions", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("metadata not found")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var versions []MetadataVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetMetadataVersion retrieves a single version of metadata.
+func (c *Client) GetMetadataVersion(ctx context.Context, id string, version int) (*MetadataVersion, error) {
+	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/metadata/%s/versions/%d", url.PathEscape(id), version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("metadata version not found")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var metadataVersion MetadataVersion
+	if err := json.NewDecoder(resp.Body).Decode(&metadataVersion); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &metadataVersion, nil
+}
+
+// Events API
+
+// Event represents an audit log entry recorded by the server for every change to a resource.
+// Before is empty for creations and After is empty for deletions.
+type Event = api.Event
+
+// EventFilter narrows the events returned by ListEvents. Zero values are ignored.
+type EventFilter struct {
+	ResourceID   string
+	ResourceType string
+	Action       string
+	Since        time.Time
+	Until        time.Time
+}
+
+// ListEvents retrieves audit events in chronological order, optionally filtered.
+func (c *Client) ListEvents(ctx context.Context, filter EventFilter) ([]Event, error) {
+	endpoint := "/events"
+	params := url.Values{}
+
+	if filter.ResourceID != "" {
+		params.Add("resource_id", filter.ResourceID)
+	}
+	if filter.ResourceType != "" {
+		params.Add("resource_type", filter.ResourceType)
+	}
+	if filter.Action != "" {
+		params.Add("action", filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		params.Add("since", filter.Since.Format(time.RFC3339Nano))
+	}
+	if !filter.Until.IsZero() {
+		params.Add("until", filter.Until.Format(time.RFC3339Nano))
+	}
+
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var events []Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return events, nil
+}
+
+// Admin API
+
+// SnapshotFormatVersion is the version of the snapshot document produced by this client.
+const SnapshotFormatVersion = 1
+
+// Snapshot is a portable JSON document holding the full state of a server.
+type Snapshot = api.Snapshot
+
+// Snapshot captures the full server state.
+func (c *Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	resp, err := c.doRequest(ctx, "POST", "/admin/snapshot", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorResponse(resp)
+	}
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// RestoreSnapshot replaces the full server state with the given snapshot.
+func (c *Client) RestoreSnapshot(ctx context.Context, snapshot Snapshot) error {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/admin/restore", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return parseErrorResponse(resp)
 	}
 
 	return nil
 }
+
+// ResetServer deletes all server state.
+func (c *Client) ResetServer(ctx context.Context) error {
+	resp, err := c.doRequest(ctx, "POST", "/admin/reset", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return parseErrorResponse(resp)
+	}
+
+	return nil
+}
+
+// DeterministicModeRequest represents the request body for enabling deterministic mode.
+type DeterministicModeRequest = api.DeterministicModeRequest
+
+// ClockRequest represents the request body for moving the server's fake clock.
+// Now, if set, is applied before Advance.
+type ClockRequest = api.ClockRequest
+
+// ClockResponse reports the server's current time.
+type ClockResponse = api.ClockResponse
+
+// EnableDeterministicMode switches the server to sequential IDs and a fake clock, and
+// deletes all state so that subsequent runs produce identical IDs and timestamps.
+func (c *Client) EnableDeterministicMode(ctx context.Context, req DeterministicModeRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/admin/deterministic", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return parseErrorResponse(resp)
+	}
+
+	return nil
+}
+
+// AdvanceClock moves the server's fake clock forward by d and returns the new server time.
+func (c *Client) AdvanceClock(ctx context.Context, d time.Duration) (time.Time, error) {
+	return c.setClock(ctx, ClockRequest{Advance: d.String()})
+}
+
+// SetClock moves the server's fake clock to t.
+func (c *Client) SetClock(ctx context.Context, t time.Time) (time.Time, error) {
+	return c.setClock(ctx, ClockRequest{Now: &t})
+}
+
+// setClock sends a ClockRequest and returns the resulting server time.
+func (c *Client) setClock(ctx context.Context, req ClockRequest) (time.Time, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/admin/clock", bytes.NewReader(body))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, parseErrorResponse(resp)
+	}
+
+	var clock ClockResponse
+	if err := json.NewDecoder(resp.Body).Decode(&clock); err != nil {
+		return time.Time{}, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return clock.Now, nil
+}
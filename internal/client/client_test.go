@@ -0,0 +1,856 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testID contains characters that must be escaped in a path segment, so every test
+// also checks that IDs are escaped consistently.
+const (
+	testID        = "a/b c"
+	testIDEscaped = "a%2Fb%20c"
+)
+
+var testTime = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// methodCase describes one client method: the request it must send and the response
+// that makes it succeed.
+type methodCase struct {
+	name string
+	call func(ctx context.Context, c *Client) (interface{}, error)
+
+	// method, path and query are the expected request line; path excludes the base URL.
+	method string
+	path   string
+	query  string
+	// request is the expected JSON request body, empty for requests without a body.
+	request string
+
+	// status and response make up the successful response; response is empty when the
+	// method does not decode a body.
+	status   int
+	response string
+	want     interface{}
+
+	// notFound is the error returned for a 404; empty means a 404 is an *APIError.
+	notFound string
+}
+
+func methodCases() []methodCase {
+	return []methodCase{
+		// Buckets
+		{
+			name: "CreateBucket",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateBucket(ctx, CreateBucketRequest{Name: "assets"})
+			},
+			method:   "POST",
+			path:     "/buckets",
+			request:  `{"name":"assets"}`,
+			status:   http.StatusCreated,
+			response: `{"id":"bkt-1","name":"assets"}`,
+			want:     &Bucket{ID: "bkt-1", Name: "assets"},
+		},
+		{
+			name:     "GetBucket",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBucket(ctx, testID) },
+			method:   "GET",
+			path:     "/buckets/" + testIDEscaped,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","name":"assets"}`,
+			want:     &Bucket{ID: testID, Name: "assets"},
+			notFound: "bucket not found",
+		},
+		{
+			name:     "ListBuckets",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.ListBuckets(ctx, "assets") },
+			method:   "GET",
+			path:     "/buckets",
+			query:    "name=assets",
+			status:   http.StatusOK,
+			response: `[{"id":"bkt-1","name":"assets"}]`,
+			want:     []Bucket{{ID: "bkt-1", Name: "assets"}},
+		},
+		{
+			name: "UpdateBucket",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateBucket(ctx, testID, UpdateBucketRequest{Name: "media"})
+			},
+			method:   "PATCH",
+			path:     "/buckets/" + testIDEscaped,
+			request:  `{"name":"media"}`,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","name":"media"}`,
+			want:     &Bucket{ID: testID, Name: "media"},
+			notFound: "bucket not found",
+		},
+		{
+			name:     "DeleteBucket",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.DeleteBucket(ctx, testID) },
+			method:   "DELETE",
+			path:     "/buckets/" + testIDEscaped,
+			status:   http.StatusNoContent,
+			notFound: "bucket not found",
+		},
+
+		// Objects
+		{
+			name: "CreateObject",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateObject(ctx, testID, CreateObjectRequest{Path: "index.html", Content: "aGk="})
+			},
+			method:   "POST",
+			path:     "/bucket/" + testIDEscaped + "/objects",
+			request:  `{"bucket_id":"a/b c","path":"index.html","content":"aGk="}`,
+			status:   http.StatusCreated,
+			response: `{"id":"obj-1","bucket_id":"a/b c","path":"index.html","content":"aGk="}`,
+			want:     &Object{ID: "obj-1", BucketID: testID, Path: "index.html", Content: "aGk="},
+		},
+		{
+			name:     "ListObjects",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.ListObjects(ctx, testID) },
+			method:   "GET",
+			path:     "/bucket/" + testIDEscaped + "/objects",
+			status:   http.StatusOK,
+			response: `[{"id":"obj-1","bucket_id":"a/b c","path":"index.html"}]`,
+			want:     []Object{{ID: "obj-1", BucketID: testID, Path: "index.html"}},
+		},
+		{
+			name:     "GetObject",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetObject(ctx, testID, "obj/1") },
+			method:   "GET",
+			path:     "/bucket/" + testIDEscaped + "/objects/obj%2F1",
+			status:   http.StatusOK,
+			response: `{"id":"obj/1","bucket_id":"a/b c","path":"index.html"}`,
+			want:     &Object{ID: "obj/1", BucketID: testID, Path: "index.html"},
+			notFound: "object not found",
+		},
+		{
+			name: "UpdateObject",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				content := "Ynll"
+				return c.UpdateObject(ctx, testID, "obj/1", UpdateObjectRequest{Content: &content})
+			},
+			method:   "PATCH",
+			path:     "/bucket/" + testIDEscaped + "/objects/obj%2F1",
+			request:  `{"content":"Ynll"}`,
+			status:   http.StatusOK,
+			response: `{"id":"obj/1","bucket_id":"a/b c","path":"index.html","content":"Ynll"}`,
+			want:     &Object{ID: "obj/1", BucketID: testID, Path: "index.html", Content: "Ynll"},
+			notFound: "object not found",
+		},
+		{
+			name: "DeleteObject",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteObject(ctx, testID, "obj/1")
+			},
+			method:   "DELETE",
+			path:     "/bucket/" + testIDEscaped + "/objects/obj%2F1",
+			status:   http.StatusNoContent,
+			notFound: "object not found",
+		},
+
+		// Projects
+		{
+			name: "CreateProject",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateProject(ctx, CreateProjectRequest{Name: "payments"})
+			},
+			method:   "POST",
+			path:     "/projects",
+			request:  `{"name":"payments"}`,
+			status:   http.StatusCreated,
+			response: `{"id":"proj-1","name":"payments","created_at":"2025-01-02T03:04:05Z"}`,
+			want:     &Project{ID: "proj-1", Name: "payments", CreatedAt: testTime},
+		},
+		{
+			name:     "GetProject",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetProject(ctx, testID) },
+			method:   "GET",
+			path:     "/projects/" + testIDEscaped,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","name":"payments"}`,
+			want:     &Project{ID: testID, Name: "payments"},
+			notFound: "project not found",
+		},
+		{
+			name:     "ListProjects",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.ListProjects(ctx, "pay ments") },
+			method:   "GET",
+			path:     "/projects",
+			query:    "name=pay+ments",
+			status:   http.StatusOK,
+			response: `[{"id":"proj-1","name":"pay ments"}]`,
+			want:     []Project{{ID: "proj-1", Name: "pay ments"}},
+		},
+		{
+			name: "UpdateProject",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateProject(ctx, testID, UpdateProjectRequest{Name: "billing"})
+			},
+			method:   "PATCH",
+			path:     "/projects/" + testIDEscaped,
+			request:  `{"name":"billing"}`,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","name":"billing"}`,
+			want:     &Project{ID: testID, Name: "billing"},
+			notFound: "project not found",
+		},
+		{
+			name:     "DeleteProject",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.DeleteProject(ctx, testID) },
+			method:   "DELETE",
+			path:     "/projects/" + testIDEscaped,
+			status:   http.StatusNoContent,
+			notFound: "project not found",
+		},
+		{
+			name:     "GetProjectQuota",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetProjectQuota(ctx, testID) },
+			method:   "GET",
+			path:     "/projects/" + testIDEscaped + "/quota",
+			status:   http.StatusOK,
+			response: `{"project_id":"a/b c","limits":{"cpu":8,"memory_mb":8192},"usage":{"cpu":2,"memory_mb":1024}}`,
+			want: &ProjectQuota{
+				ProjectID: testID,
+				Limits:    ResourceAmounts{CPU: 8, MemoryMB: 8192},
+				Usage:     ResourceAmounts{CPU: 2, MemoryMB: 1024},
+			},
+			notFound: "project quota not found",
+		},
+
+		// Instances
+		{
+			name: "CreateInstance",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateInstance(ctx, CreateInstanceRequest{ProjectID: "proj-1", Name: "web", CPU: 2, MemoryMB: 512, Image: "ubuntu"})
+			},
+			method:   "POST",
+			path:     "/instances",
+			request:  `{"project_id":"proj-1","name":"web","cpu":2,"memory_mb":512,"image":"ubuntu"}`,
+			status:   http.StatusCreated,
+			response: `{"id":"inst-1","project_id":"proj-1","name":"web","cpu":2,"memory_mb":512,"image":"ubuntu","status":"running"}`,
+			want:     &Instance{ID: "inst-1", ProjectID: "proj-1", Name: "web", CPU: 2, MemoryMB: 512, Image: "ubuntu", Status: "running"},
+		},
+		{
+			name:     "GetInstance",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetInstance(ctx, testID) },
+			method:   "GET",
+			path:     "/instances/" + testIDEscaped,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","name":"web"}`,
+			want:     &Instance{ID: testID, Name: "web"},
+			notFound: "instance not found",
+		},
+		{
+			name: "ListInstances",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ListInstances(ctx, "proj-1", "web", "running")
+			},
+			method:   "GET",
+			path:     "/instances",
+			query:    "name=web&project_id=proj-1&status=running",
+			status:   http.StatusOK,
+			response: `[{"id":"inst-1","project_id":"proj-1","name":"web","status":"running"}]`,
+			want:     []Instance{{ID: "inst-1", ProjectID: "proj-1", Name: "web", Status: "running"}},
+		},
+		{
+			name: "UpdateInstance",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				cpu := 4
+				return c.UpdateInstance(ctx, testID, UpdateInstanceRequest{CPU: &cpu})
+			},
+			method:   "PATCH",
+			path:     "/instances/" + testIDEscaped,
+			request:  `{"cpu":4}`,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","cpu":4}`,
+			want:     &Instance{ID: testID, CPU: 4},
+			notFound: "instance not found",
+		},
+		{
+			name:     "DeleteInstance",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.DeleteInstance(ctx, testID) },
+			method:   "DELETE",
+			path:     "/instances/" + testIDEscaped,
+			status:   http.StatusNoContent,
+			notFound: "instance not found",
+		},
+
+		// Metadata
+		{
+			name: "CreateMetadata",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateMetadata(ctx, CreateMetadataRequest{Path: "app/env", Value: "prod"})
+			},
+			method:   "POST",
+			path:     "/metadata",
+			request:  `{"path":"app/env","value":"prod"}`,
+			status:   http.StatusCreated,
+			response: `{"id":"meta-1","path":"app/env","value":"prod"}`,
+			want:     &Metadata{ID: "meta-1", Path: "app/env", Value: "prod"},
+		},
+		{
+			name:     "GetMetadata",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetMetadata(ctx, testID) },
+			method:   "GET",
+			path:     "/metadata/" + testIDEscaped,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","path":"app/env","value":"prod"}`,
+			want:     &Metadata{ID: testID, Path: "app/env", Value: "prod"},
+			notFound: "metadata not found",
+		},
+		{
+			name:     "GetMetadataByPath",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetMetadataByPath(ctx, "app/env") },
+			method:   "GET",
+			path:     "/metadata",
+			query:    "prefix=app%2Fenv",
+			status:   http.StatusOK,
+			response: `[{"id":"meta-2","path":"app/env2"},{"id":"meta-1","path":"app/env","value":"prod"}]`,
+			want:     &Metadata{ID: "meta-1", Path: "app/env", Value: "prod"},
+		},
+		{
+			name:     "ListMetadata",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.ListMetadata(ctx, "app/") },
+			method:   "GET",
+			path:     "/metadata",
+			query:    "prefix=app%2F",
+			status:   http.StatusOK,
+			response: `[{"id":"meta-1","path":"app/env","value":"prod"}]`,
+			want:     []Metadata{{ID: "meta-1", Path: "app/env", Value: "prod"}},
+		},
+		{
+			name: "UpdateMetadata",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				value := "staging"
+				return c.UpdateMetadata(ctx, testID, UpdateMetadataRequest{Value: &value})
+			},
+			method:   "PATCH",
+			path:     "/metadata/" + testIDEscaped,
+			request:  `{"value":"staging"}`,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","path":"app/env","value":"staging"}`,
+			want:     &Metadata{ID: testID, Path: "app/env", Value: "staging"},
+			notFound: "metadata not found",
+		},
+		{
+			name:     "DeleteMetadata",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.DeleteMetadata(ctx, testID) },
+			method:   "DELETE",
+			path:     "/metadata/" + testIDEscaped,
+			status:   http.StatusNoContent,
+			notFound: "metadata not found",
+		},
+		{
+			name: "CreateMetadataIfAbsent",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateMetadataIfAbsent(ctx, CreateMetadataRequest{Path: "locks/deploy", Value: "runner-1"})
+			},
+			method:   "POST",
+			path:     "/metadata",
+			request:  `{"path":"locks/deploy","value":"runner-1"}`,
+			status:   http.StatusCreated,
+			response: `{"id":"meta-1","path":"locks/deploy","value":"runner-1","version":1}`,
+			want:     &Metadata{ID: "meta-1", Path: "locks/deploy", Value: "runner-1", Version: 1},
+		},
+		{
+			name: "CompareAndSwapMetadata",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				value, version := "2", 1
+				return c.CompareAndSwapMetadata(ctx, testID, UpdateMetadataRequest{Value: &value, IfVersion: &version})
+			},
+			method:   "PATCH",
+			path:     "/metadata/" + testIDEscaped,
+			request:  `{"value":"2","if_version":1}`,
+			status:   http.StatusOK,
+			response: `{"id":"a/b c","path":"counters/builds","value":"2","version":2}`,
+			want:     &Metadata{ID: testID, Path: "counters/builds", Value: "2", Version: 2},
+			notFound: "metadata not found",
+		},
+		{
+			name: "DeleteMetadataIfVersion",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteMetadataIfVersion(ctx, testID, 3)
+			},
+			method:   "DELETE",
+			path:     "/metadata/" + testIDEscaped,
+			query:    "if_version=3",
+			status:   http.StatusNoContent,
+			notFound: "metadata not found",
+		},
+		{
+			name:     "ListMetadataVersions",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.ListMetadataVersions(ctx, testID) },
+			method:   "GET",
+			path:     "/metadata/" + testIDEscaped + "/versions",
+			status:   http.StatusOK,
+			response: `[{"metadata_id":"a/b c","version":1,"value":"prod"},{"metadata_id":"a/b c","version":2,"value":"staging"}]`,
+			want:     []MetadataVersion{{MetadataID: testID, Version: 1, Value: "prod"}, {MetadataID: testID, Version: 2, Value: "staging"}},
+			notFound: "metadata not found",
+		},
+		{
+			name:     "GetMetadataVersion",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetMetadataVersion(ctx, testID, 2) },
+			method:   "GET",
+			path:     "/metadata/" + testIDEscaped + "/versions/2",
+			status:   http.StatusOK,
+			response: `{"metadata_id":"a/b c","version":2,"value":"staging"}`,
+			want:     &MetadataVersion{MetadataID: testID, Version: 2, Value: "staging"},
+			notFound: "metadata version not found",
+		},
+
+		// Events
+		{
+			name: "ListEvents",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ListEvents(ctx, EventFilter{
+					ResourceID:   "inst-1",
+					ResourceType: "instance",
+					Action:       "update",
+					Since:        testTime,
+					Until:        testTime.Add(time.Hour),
+				})
+			},
+			method:   "GET",
+			path:     "/events",
+			query:    "action=update&resource_id=inst-1&resource_type=instance&since=2025-01-02T03%3A04%3A05Z&until=2025-01-02T04%3A04%3A05Z",
+			status:   http.StatusOK,
+			response: `[{"id":"evt-1","action":"update","resource_type":"instance","resource_id":"inst-1","after":{"cpu":4},"timestamp":"2025-01-02T03:04:05Z"}]`,
+			want: []Event{{
+				ID:           "evt-1",
+				Action:       "update",
+				ResourceType: "instance",
+				ResourceID:   "inst-1",
+				After:        map[string]interface{}{"cpu": float64(4)},
+				Timestamp:    testTime,
+			}},
+		},
+
+		// Admin
+		{
+			name:     "Snapshot",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.Snapshot(ctx) },
+			method:   "POST",
+			path:     "/admin/snapshot",
+			status:   http.StatusOK,
+			response: `{"version":1,"projects":[{"id":"proj-1","name":"payments"}]}`,
+			want:     &Snapshot{Version: 1, Projects: []Project{{ID: "proj-1", Name: "payments"}}},
+		},
+		{
+			name: "RestoreSnapshot",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.RestoreSnapshot(ctx, Snapshot{Version: SnapshotFormatVersion})
+			},
+			method:  "POST",
+			path:    "/admin/restore",
+			request: `{"version":1,"projects":null,"instances":null,"metadata":null,"buckets":null,"objects":null}`,
+			status:  http.StatusNoContent,
+		},
+		{
+			name:   "ResetServer",
+			call:   func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.ResetServer(ctx) },
+			method: "POST",
+			path:   "/admin/reset",
+			status: http.StatusNoContent,
+		},
+		{
+			name: "EnableDeterministicMode",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.EnableDeterministicMode(ctx, DeterministicModeRequest{Seed: 100, Start: testTime})
+			},
+			method:  "POST",
+			path:    "/admin/deterministic",
+			request: `{"seed":100,"start":"2025-01-02T03:04:05Z"}`,
+			status:  http.StatusNoContent,
+		},
+		{
+			name:     "AdvanceClock",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.AdvanceClock(ctx, time.Hour) },
+			method:   "POST",
+			path:     "/admin/clock",
+			request:  `{"advance":"1h0m0s"}`,
+			status:   http.StatusOK,
+			response: `{"now":"2025-01-02T04:04:05Z"}`,
+			want:     testTime.Add(time.Hour),
+		},
+		{
+			name:     "SetClock",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.SetClock(ctx, testTime) },
+			method:   "POST",
+			path:     "/admin/clock",
+			request:  `{"now":"2025-01-02T03:04:05Z"}`,
+			status:   http.StatusOK,
+			response: `{"now":"2025-01-02T03:04:05Z"}`,
+			want:     testTime,
+		},
+	}
+}
+
+// newTestClient returns a client for a test server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.URL + "/v1")
+	c.Token = "secret-token"
+	c.UserAgent = "terraform-provider-dirt/test"
+	return c
+}
+
+// checkRequest fails the test if r is not the request described by tc.
+func checkRequest(t *testing.T, tc methodCase, r *http.Request) {
+	t.Helper()
+
+	if r.Method != tc.method {
+		t.Errorf("method = %s, want %s", r.Method, tc.method)
+	}
+	if got := r.URL.EscapedPath(); got != "/v1"+tc.path {
+		t.Errorf("path = %s, want /v1%s", got, tc.path)
+	}
+	if r.URL.RawQuery != tc.query {
+		t.Errorf("query = %q, want %q", r.URL.RawQuery, tc.query)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("reading request body: %s", err)
+	}
+
+	if tc.request == "" {
+		if len(body) != 0 {
+			t.Errorf("unexpected request body %s", body)
+		}
+		return
+	}
+
+	if got := r.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var got, want interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("request body %s is not JSON: %s", body, err)
+	}
+	if err := json.Unmarshal([]byte(tc.request), &want); err != nil {
+		t.Fatalf("test case request %s is not JSON: %s", tc.request, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("request body = %s, want %s", body, tc.request)
+	}
+}
+
+// respond returns a handler that checks the request against tc and answers with the
+// given status and body.
+func respond(t *testing.T, tc methodCase, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checkRequest(t, tc, r)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+// wantAPIError fails the test unless err is an *APIError with the given status, code
+// and a message containing message.
+func wantAPIError(t *testing.T, err error, status int, code, message string) {
+	t.Helper()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v (%T), want *APIError", err, err)
+	}
+	if apiErr.StatusCode != status {
+		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, status)
+	}
+	if apiErr.Code != code {
+		t.Errorf("Code = %q, want %q", apiErr.Code, code)
+	}
+	if !strings.Contains(apiErr.Message, message) {
+		t.Errorf("Message = %q, want it to contain %q", apiErr.Message, message)
+	}
+}
+
+func TestClientMethods(t *testing.T) {
+	for _, tc := range methodCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("success", func(t *testing.T) {
+				c := newTestClient(t, respond(t, tc, tc.status, tc.response))
+
+				got, err := tc.call(context.Background(), c)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("result = %#v, want %#v", got, tc.want)
+				}
+			})
+
+			t.Run("not found", func(t *testing.T) {
+				c := newTestClient(t, respond(t, tc, http.StatusNotFound, `{"error":"not_found","message":"no such thing"}`))
+
+				_, err := tc.call(context.Background(), c)
+				if tc.notFound == "" {
+					wantAPIError(t, err, http.StatusNotFound, "not_found", "no such thing")
+					return
+				}
+				if err == nil || err.Error() != tc.notFound {
+					t.Errorf("error = %v, want %q", err, tc.notFound)
+				}
+			})
+
+			t.Run("error response", func(t *testing.T) {
+				c := newTestClient(t, respond(t, tc, http.StatusConflict, `{"error":"conflict","message":"already exists"}`))
+
+				_, err := tc.call(context.Background(), c)
+				wantAPIError(t, err, http.StatusConflict, "conflict", "already exists")
+				if err.Error() != "HTTP 409: already exists" {
+					t.Errorf("error = %q, want %q", err, "HTTP 409: already exists")
+				}
+			})
+
+			t.Run("error without message", func(t *testing.T) {
+				c := newTestClient(t, respond(t, tc, http.StatusBadRequest, `{"error":"invalid"}`))
+
+				_, err := tc.call(context.Background(), c)
+				wantAPIError(t, err, http.StatusBadRequest, "invalid", "400 Bad Request")
+			})
+
+			t.Run("non-JSON error body", func(t *testing.T) {
+				c := newTestClient(t, respond(t, tc, http.StatusBadGateway, "<html>bad gateway</html>"))
+
+				_, err := tc.call(context.Background(), c)
+				wantAPIError(t, err, http.StatusBadGateway, "", "502 Bad Gateway (failed to parse error response)")
+			})
+
+			if tc.response != "" {
+				t.Run("malformed response", func(t *testing.T) {
+					c := newTestClient(t, respond(t, tc, tc.status, `{"id":`))
+
+					_, err := tc.call(context.Background(), c)
+					if err == nil || !strings.HasPrefix(err.Error(), "decoding response: ") {
+						t.Errorf("error = %v, want a decoding error", err)
+					}
+				})
+			}
+
+			t.Run("canceled context", func(t *testing.T) {
+				c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+					t.Error("request sent despite canceled context")
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := tc.call(ctx, c)
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("error = %v, want context.Canceled", err)
+				}
+			})
+		})
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	tests := map[string]struct {
+		token     string
+		userAgent string
+		wantAuth  string
+	}{
+		"token": {
+			token:     "secret-token",
+			userAgent: "terraform-provider-dirt/1.2.3",
+			wantAuth:  "Bearer secret-token",
+		},
+		"no token": {
+			userAgent: "terraform-provider-dirt/1.2.3",
+		},
+		"no user agent": {
+			token:    "secret-token",
+			wantAuth: "Bearer secret-token",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var header http.Header
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				w.WriteHeader(http.StatusNoContent)
+			})
+			c.Token = tt.token
+			c.UserAgent = tt.userAgent
+
+			if err := c.DeleteProject(context.Background(), "proj-1"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if _, ok := header["Authorization"]; tt.wantAuth == "" && ok {
+				t.Errorf("Authorization header sent without a token: %q", header.Get("Authorization"))
+			}
+			if got := header.Get("Authorization"); got != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+			}
+			if tt.userAgent != "" && header.Get("User-Agent") != tt.userAgent {
+				t.Errorf("User-Agent = %q, want %q", header.Get("User-Agent"), tt.userAgent)
+			}
+			if got := header.Get("Content-Type"); got != "" {
+				t.Errorf("Content-Type = %q on a request without body", got)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Setenv("DIRT_TOKEN", "env-token")
+
+	c := NewClient("")
+	if c.BaseURL != "http://localhost:8080/v1" {
+		t.Errorf("BaseURL = %q, want the default endpoint", c.BaseURL)
+	}
+	if c.Token != "env-token" {
+		t.Errorf("Token = %q, want the value of DIRT_TOKEN", c.Token)
+	}
+	if c.UserAgent != "terraform-provider-dirt" {
+		t.Errorf("UserAgent = %q, want terraform-provider-dirt", c.UserAgent)
+	}
+	if c.HTTPClient == nil || c.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("HTTPClient = %#v, want a client with a 30s timeout", c.HTTPClient)
+	}
+
+	if c := NewClient("https://dirt.example.com/v1"); c.BaseURL != "https://dirt.example.com/v1" {
+		t.Errorf("BaseURL = %q, want the given endpoint", c.BaseURL)
+	}
+}
+
+func TestClientTransportErrors(t *testing.T) {
+	t.Run("invalid base URL", func(t *testing.T) {
+		c := NewClient("http://dirt.example.com/\x7f")
+
+		_, err := c.GetProject(context.Background(), "proj-1")
+		if err == nil || !strings.HasPrefix(err.Error(), "creating request: ") {
+			t.Errorf("error = %v, want a request creation error", err)
+		}
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		_, err := NewClient(srv.URL+"/v1").GetProject(context.Background(), "proj-1")
+		if err == nil || !strings.HasPrefix(err.Error(), "executing request: ") {
+			t.Errorf("error = %v, want a transport error", err)
+		}
+	})
+}
+
+func TestGetMetadataByPath_noExactMatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `[{"id":"meta-2","path":"app/env2"}]`)
+	})
+
+	_, err := c.GetMetadataByPath(context.Background(), "app/env")
+	if err == nil || err.Error() != "metadata not found" {
+		t.Errorf("error = %v, want %q", err, "metadata not found")
+	}
+}
+
+func TestMetadata_expired(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"expired","message":"metadata expired at 2025-01-01T01:00:00Z"}`)
+	})
+
+	_, err := c.GetMetadata(context.Background(), "meta-1")
+	wantAPIError(t, err, http.StatusNotFound, "expired", "metadata expired at 2025-01-01T01:00:00Z")
+
+	value := "staging"
+	_, err = c.UpdateMetadata(context.Background(), "meta-1", UpdateMetadataRequest{Value: &value})
+	wantAPIError(t, err, http.StatusNotFound, "expired", "metadata expired at 2025-01-01T01:00:00Z")
+}
+
+func TestConditionalMetadata_conflict(t *testing.T) {
+	for _, status := range []int{http.StatusConflict, http.StatusPreconditionFailed} {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, `{"error":"precondition_failed","message":"metadata is at version 2, not 1"}`)
+		})
+
+		value, version := "2", 1
+		_, casErr := c.CompareAndSwapMetadata(context.Background(), "meta-1", UpdateMetadataRequest{Value: &value, IfVersion: &version})
+		_, createErr := c.CreateMetadataIfAbsent(context.Background(), CreateMetadataRequest{Path: "locks/deploy", Value: "runner-1"})
+		deleteErr := c.DeleteMetadataIfVersion(context.Background(), "meta-1", 1)
+
+		for _, err := range []error{casErr, createErr, deleteErr} {
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("HTTP %d: error = %v (%T), want *ConflictError", status, err, err)
+			}
+			wantAPIError(t, err, status, "precondition_failed", "metadata is at version 2, not 1")
+		}
+	}
+
+	// Plain writes keep reporting conflicts as an *APIError
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = io.WriteString(w, `{"error":"conflict","message":"already exists"}`)
+	})
+	_, err := c.CreateMetadata(context.Background(), CreateMetadataRequest{Path: "locks/deploy"})
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		t.Errorf("CreateMetadata error = %T, want *APIError", err)
+	}
+}
+
+func TestCompareAndSwapMetadata_noCondition(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent without a condition")
+	})
+
+	value := "2"
+	_, err := c.CompareAndSwapMetadata(context.Background(), "meta-1", UpdateMetadataRequest{Value: &value})
+	if err == nil || err.Error() != "compare-and-swap requires IfValue or IfVersion" {
+		t.Errorf("error = %v, want a missing condition error", err)
+	}
+}
+
+func TestGetProjectQuota_notImplemented(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	})
+
+	_, err := c.GetProjectQuota(context.Background(), "proj-1")
+	if !errors.Is(err, ErrQuotaNotFound) {
+		t.Errorf("error = %v, want ErrQuotaNotFound", err)
+	}
+}
+
+func TestListInstances_noFilters(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("query = %q, want none", r.URL.RawQuery)
+		}
+		_, _ = io.WriteString(w, `[]`)
+	})
+
+	instances, err := c.ListInstances(context.Background(), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(instances) != 0 {
+		t.Errorf("instances = %#v, want none", instances)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxMetadataPathLength is the maximum length of a metadata path in bytes.
+	MaxMetadataPathLength = 256
+	// MaxMetadataPathDepth is the maximum number of segments in a metadata path.
+	MaxMetadataPathDepth = 16
+)
+
+// metadataPathSegment matches a single segment of a metadata path.
+var metadataPathSegment = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidateMetadataPath reports whether path is a canonical metadata path: one to
+// MaxMetadataPathDepth segments of letters, digits, '_', '.' and '-', separated by
+// single slashes, at most MaxMetadataPathLength bytes long. Segments "." and ".." are
+// not allowed. The server rejects any other path.
+func ValidateMetadataPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if len(path) > MaxMetadataPathLength {
+		return fmt.Errorf("path must be at most %d characters, got %d", MaxMetadataPathLength, len(path))
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) > MaxMetadataPathDepth {
+		return fmt.Errorf("path must have at most %d segments, got %d", MaxMetadataPathDepth, len(segments))
+	}
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+			return fmt.Errorf("path %q must not start or end with a slash or contain empty segments", path)
+		case segment == "." || segment == "..":
+			return fmt.Errorf("path %q must not contain %q segments", path, segment)
+		case !metadataPathSegment.MatchString(segment):
+			return fmt.Errorf("path segment %q must only contain letters, digits, '_', '.' and '-'", segment)
+		}
+	}
+	return nil
+}
+
+// NormalizeMetadataPath returns path in canonical form, without surrounding
+// whitespace, leading or trailing slashes and repeated slashes, so that e.g.
+// "/app//config/" and "app/config" address the same entry. The result still needs
+// ValidateMetadataPath.
+func NormalizeMetadataPath(path string) string {
+	segments := strings.FieldsFunc(strings.TrimSpace(path), func(r rune) bool { return r == '/' })
+	return strings.Join(segments, "/")
+}
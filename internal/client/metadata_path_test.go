@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadataPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "app", want: ""},
+		{path: "app/config/database_url", want: ""},
+		{path: "tf-acc/v1.2.3/x_y", want: ""},
+		{path: strings.Repeat("a/", MaxMetadataPathDepth-1) + "a", want: ""},
+		{path: strings.Repeat("a", MaxMetadataPathLength), want: ""},
+		{path: "", want: "path must not be empty"},
+		{path: strings.Repeat("a", MaxMetadataPathLength+1), want: "path must be at most 256 characters, got 257"},
+		{path: strings.Repeat("a/", MaxMetadataPathDepth) + "a", want: "path must have at most 16 segments, got 17"},
+		{path: "/app", want: `path "/app" must not start or end with a slash or contain empty segments`},
+		{path: "app/", want: `path "app/" must not start or end with a slash or contain empty segments`},
+		{path: "app//config", want: `path "app//config" must not start or end with a slash or contain empty segments`},
+		{path: "app/../config", want: `path "app/../config" must not contain ".." segments`},
+		{path: "app/./config", want: `path "app/./config" must not contain "." segments`},
+		{path: "app/my config", want: `path segment "my config" must only contain letters, digits, '_', '.' and '-'`},
+		{path: " app", want: `path segment " app" must only contain letters, digits, '_', '.' and '-'`},
+		{path: "app/ünïcode", want: `path segment "ünïcode" must only contain letters, digits, '_', '.' and '-'`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			err := ValidateMetadataPath(tc.path)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Errorf("ValidateMetadataPath(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMetadataPath(t *testing.T) {
+	cases := map[string]string{
+		"app/config":          "app/config",
+		"/app/config":         "app/config",
+		"app/config/":         "app/config",
+		"app//config":         "app/config",
+		"  /app///config//  ": "app/config",
+		"app/my config":       "app/my config",
+		"/":                   "",
+		"":                    "",
+	}
+
+	for path, want := range cases {
+		if got := NormalizeMetadataPath(path); got != want {
+			t.Errorf("NormalizeMetadataPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
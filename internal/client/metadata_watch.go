@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/api"
+)
+
+// MetadataEvent is a change to metadata delivered by WatchMetadata. The values of
+// secrets are blank.
+type MetadataEvent = api.MetadataEvent
+
+// MetadataEventType is the kind of change a MetadataEvent reports.
+type MetadataEventType = api.MetadataEventType
+
+// Metadata event types. A reset means all metadata was replaced, e.g. by restoring a
+// snapshot; it is followed by a create event for every entry that remains.
+const (
+	MetadataEventCreate = api.MetadataEventCreate
+	MetadataEventUpdate = api.MetadataEventUpdate
+	MetadataEventDelete = api.MetadataEventDelete
+	MetadataEventReset  = api.MetadataEventReset
+)
+
+// Reconnect delays of a metadata watch, and how long it waits for data before it
+// considers the connection dead. The server sends heartbeats well within that time.
+var (
+	watchRetryMin    = 100 * time.Millisecond
+	watchRetryMax    = 5 * time.Second
+	watchIdleTimeout = time.Minute
+)
+
+// MetadataWatch is a running watch started by WatchMetadata.
+type MetadataWatch struct {
+	events   chan MetadataEvent
+	err      error
+	revision atomic.Int64
+}
+
+// Events returns the channel the changes are delivered on, in revision order. It is
+// closed when the watch ends; callers must keep receiving or cancel the context.
+func (w *MetadataWatch) Events() <-chan MetadataEvent {
+	return w.events
+}
+
+// Err returns why the watch ended once Events is closed: the context's error, or the
+// error the server answered a reconnect with, e.g. an *APIError with status 410 when
+// the changes since Revision are no longer retained.
+func (w *MetadataWatch) Err() error {
+	return w.err
+}
+
+// Revision returns the revision the watch has seen changes up to. Passing it to
+// WatchMetadata resumes an ended watch.
+func (w *MetadataWatch) Revision() int64 {
+	return w.revision.Load()
+}
+
+// WatchMetadata streams changes to metadata whose path starts with prefix, starting
+// after sinceRevision, or after the latest revision if it is 0. Dropped connections are
+// reopened with backoff, resuming after the last revision seen. An error connecting the
+// first time is returned directly.
+func (c *Client) WatchMetadata(ctx context.Context, prefix string, sinceRevision int64) (*MetadataWatch, error) {
+	since := sinceRevision
+	if since == 0 {
+		since = -1
+	}
+
+	resp, err := c.openMetadataWatch(ctx, prefix, since)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &MetadataWatch{events: make(chan MetadataEvent)}
+	w.revision.Store(sinceRevision)
+	if revision, err := strconv.ParseInt(resp.Header.Get("X-Dirt-Revision"), 10, 64); err == nil {
+		w.revision.Store(revision)
+	}
+
+	go w.run(ctx, c, prefix, resp)
+	return w, nil
+}
+
+// openMetadataWatch opens the event stream of changes after revision since, or after
+// the latest revision if since is negative.
+func (c *Client) openMetadataWatch(ctx context.Context, prefix string, since int64) (*http.Response, error) {
+	params := url.Values{}
+	if prefix != "" {
+		params.Set("prefix", prefix)
+	}
+	if since >= 0 {
+		params.Set("since", strconv.FormatInt(since, 10))
+	}
+	endpoint := "/metadata/watch"
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := c.newRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream stays open indefinitely, so the client's timeout must not apply
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, parseErrorResponse(resp)
+	}
+
+	return resp, nil
+}
+
+// run delivers the events of resp and of the streams reopened after it, until the
+// context is done or reopening fails for good.
+func (w *MetadataWatch) run(ctx context.Context, c *Client, prefix string, resp *http.Response) {
+	defer close(w.events)
+
+	delay := watchRetryMin
+	for {
+		received, err := w.read(ctx, resp.Body)
+		_ = resp.Body.Close()
+		if ctx.Err() != nil {
+			w.err = ctx.Err()
+			return
+		}
+		var decodeErr *watchDecodeError
+		if errors.As(err, &decodeErr) {
+			w.err = err
+			return
+		}
+		if received {
+			delay = watchRetryMin
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.err = ctx.Err()
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, watchRetryMax)
+
+			resp, err = c.openMetadataWatch(ctx, prefix, w.Revision())
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				w.err = ctx.Err()
+				return
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+				w.err = err
+				return
+			}
+		}
+	}
+}
+
+// watchDecodeError reports an event the client cannot decode. Reconnecting would
+// deliver it again, so it ends the watch.
+type watchDecodeError struct {
+	err error
+}
+
+func (e *watchDecodeError) Error() string { return fmt.Sprintf("decoding metadata event: %s", e.err) }
+func (e *watchDecodeError) Unwrap() error { return e.err }
+
+// read delivers the server-sent events in body until the stream ends or fails, and
+// reports whether anything was received.
+func (w *MetadataWatch) read(ctx context.Context, body io.ReadCloser) (bool, error) {
+	// Closing the body unblocks the read below once the connection has gone quiet
+	idle := time.AfterFunc(watchIdleTimeout, func() { _ = body.Close() })
+	defer idle.Stop()
+
+	received := false
+	reader := bufio.NewReader(body)
+	var id string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return received, err
+		}
+		idle.Reset(watchIdleTimeout)
+		received = true
+
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				id = value
+			case "data":
+				data = append(data, value)
+			}
+			continue
+		}
+
+		// A blank line ends an event; one without data only moves the revision on
+		if len(data) > 0 {
+			var e MetadataEvent
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e); err != nil {
+				return received, &watchDecodeError{err: err}
+			}
+			if err := w.deliver(ctx, e); err != nil {
+				return received, err
+			}
+		} else if revision, err := strconv.ParseInt(id, 10, 64); err == nil && revision > w.Revision() {
+			w.revision.Store(revision)
+		}
+		id, data = "", nil
+	}
+}
+
+// deliver sends e unless it was already delivered before a reconnect.
+func (w *MetadataWatch) deliver(ctx context.Context, e MetadataEvent) error {
+	if e.Revision <= w.Revision() {
+		return nil
+	}
+
+	select {
+	case w.events <- e:
+		w.revision.Store(e.Revision)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
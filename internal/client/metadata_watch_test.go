@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// receive returns the next event of w, failing the test if none arrives in time.
+func receive(t *testing.T, w *MetadataWatch) MetadataEvent {
+	t.Helper()
+
+	select {
+	case e, ok := <-w.Events():
+		if !ok {
+			t.Fatalf("watch ended: %v", w.Err())
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a metadata event")
+	}
+	return MetadataEvent{}
+}
+
+// waitEnded waits for the events of w to be closed and returns why.
+func waitEnded(t *testing.T, w *MetadataWatch) error {
+	t.Helper()
+
+	for {
+		select {
+		case e, ok := <-w.Events():
+			if !ok {
+				return w.Err()
+			}
+			t.Errorf("unexpected event at revision %d", e.Revision)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the watch to end")
+		}
+	}
+}
+
+func TestWatchMetadata(t *testing.T) {
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.EscapedPath(); got != "/v1/metadata/watch" {
+			t.Errorf("path = %s, want /v1/metadata/watch", got)
+		}
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("Accept = %q, want text/event-stream", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret-token" {
+			t.Errorf("Authorization = %q, want the client token", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		switch connections.Add(1) {
+		case 1:
+			// Starts at the latest revision, then drops the connection
+			if r.URL.RawQuery != "prefix=app%2F" {
+				t.Errorf("query = %q, want only the prefix", r.URL.RawQuery)
+			}
+			w.Header().Set("X-Dirt-Revision", "5")
+			_, _ = io.WriteString(w, ": heartbeat\n\n"+
+				"id: 6\nevent: create\ndata: {\"revision\":6,\"type\":\"create\",\n"+
+				"data: \"metadata\":{\"id\":\"meta-1\",\"path\":\"app/env\",\"value\":\"prod\"}}\n\n"+
+				"id: 8\n\n")
+		case 2:
+			// Resumes after the progress reported past revision 6
+			if r.URL.RawQuery != "prefix=app%2F&since=8" {
+				t.Errorf("query = %q, want the prefix and since=8", r.URL.RawQuery)
+			}
+			_, _ = io.WriteString(w, "id: 6\nevent: create\ndata: {\"revision\":6,\"type\":\"create\"}\n\n"+
+				"id: 9\r\nevent: delete\r\ndata: {\"revision\":9,\"type\":\"delete\",\"metadata\":{\"id\":\"meta-1\",\"path\":\"app/env\"}}\r\n\r\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			t.Errorf("unexpected connection %d", connections.Load())
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := c.WatchMetadata(ctx, "app/", 0)
+	if err != nil {
+		t.Fatalf("WatchMetadata: %s", err)
+	}
+
+	created := receive(t, w)
+	if created.Revision != 6 || created.Type != MetadataEventCreate || created.Metadata == nil || created.Metadata.Value != "prod" {
+		t.Errorf("first event = %+v, want the creation of app/env at revision 6", created)
+	}
+
+	// The replayed revision 6 is not delivered again
+	deleted := receive(t, w)
+	if deleted.Revision != 9 || deleted.Type != MetadataEventDelete {
+		t.Errorf("second event = %+v, want a deletion at revision 9", deleted)
+	}
+
+	cancel()
+	if err := waitEnded(t, w); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+	if got := w.Revision(); got != 9 {
+		t.Errorf("Revision() = %d, want 9", got)
+	}
+}
+
+func TestWatchMetadata_since(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "since=3" {
+			t.Errorf("query = %q, want since=3", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusGone)
+		_, _ = io.WriteString(w, `{"error":"revision_unavailable","message":"revision 3 is not available"}`)
+	})
+
+	_, err := c.WatchMetadata(context.Background(), "", 3)
+	wantAPIError(t, err, http.StatusGone, "revision_unavailable", "revision 3 is not available")
+}
+
+func TestWatchMetadata_reconnect(t *testing.T) {
+	defer func(idle time.Duration) { watchIdleTimeout = idle }(watchIdleTimeout)
+	watchIdleTimeout = 200 * time.Millisecond
+
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch connections.Add(1) {
+		case 1:
+			// Goes quiet without closing the connection
+			w.Header().Set("X-Dirt-Revision", "2")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"unavailable","message":"the server is shutting down"}`)
+		default:
+			if r.URL.RawQuery != "since=2" {
+				t.Errorf("query = %q, want since=2", r.URL.RawQuery)
+			}
+			w.WriteHeader(http.StatusGone)
+			_, _ = io.WriteString(w, `{"error":"revision_unavailable","message":"revision 2 is not available"}`)
+		}
+	})
+
+	w, err := c.WatchMetadata(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("WatchMetadata: %s", err)
+	}
+
+	// Server errors are retried; the revision being gone ends the watch
+	wantAPIError(t, waitEnded(t, w), http.StatusGone, "revision_unavailable", "revision 2 is not available")
+	if got := connections.Load(); got != 3 {
+		t.Errorf("connections = %d, want 3", got)
+	}
+	if got := w.Revision(); got != 2 {
+		t.Errorf("Revision() = %d, want 2", got)
+	}
+}
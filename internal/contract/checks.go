@@ -0,0 +1,1195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package contract
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// missingID is an ID no server is expected to have issued.
+const missingID = "contract-missing-id"
+
+// Checks returns every conformance check, in the order they are reported.
+func Checks() []Check {
+	return []Check{
+		{Name: "projects/crud", Run: checkProjectCRUD},
+		{Name: "projects/not-found", Run: checkProjectNotFound},
+		{Name: "projects/duplicate-name", Run: checkProjectDuplicateName},
+		{Name: "projects/delete-with-instances", Run: checkProjectDeleteWithInstances},
+		{Name: "projects/quota", Run: checkProjectQuota},
+
+		{Name: "instances/crud", Run: checkInstanceCRUD},
+		{Name: "instances/partial-update", Run: checkInstancePartialUpdate},
+		{Name: "instances/not-found", Run: checkInstanceNotFound},
+		{Name: "instances/unknown-project", Run: checkInstanceUnknownProject},
+		{Name: "instances/immutable-image", Run: checkInstanceImmutableImage},
+
+		{Name: "metadata/crud", Run: checkMetadataCRUD},
+		{Name: "metadata/partial-update", Run: checkMetadataPartialUpdate},
+		{Name: "metadata/not-found", Run: checkMetadataNotFound},
+		{Name: "metadata/duplicate-path", Run: checkMetadataDuplicatePath},
+		{Name: "metadata/invalid-path", Run: checkMetadataInvalidPath},
+		{Name: "metadata/secret", Run: checkMetadataSecret},
+		{Name: "metadata/versions", Run: checkMetadataVersions},
+		{Name: "metadata/compare-and-swap", Run: checkMetadataCompareAndSwap},
+		{Name: "metadata/watch", Run: checkMetadataWatch},
+
+		{Name: "buckets/crud", Run: checkBucketCRUD},
+		{Name: "buckets/not-found", Run: checkBucketNotFound},
+		{Name: "buckets/duplicate-name", Run: checkBucketDuplicateName},
+
+		{Name: "objects/crud", Run: checkObjectCRUD},
+		{Name: "objects/partial-update", Run: checkObjectPartialUpdate},
+		{Name: "objects/not-found", Run: checkObjectNotFound},
+		{Name: "objects/duplicate-path", Run: checkObjectDuplicatePath},
+
+		{Name: "events/list", Run: checkEvents},
+
+		{Name: "admin/snapshot", Run: checkSnapshot},
+		{Name: "admin/reset-restore", Destructive: true, Run: checkResetRestore},
+		{Name: "admin/deterministic-clock", Destructive: true, Run: checkDeterministicClock},
+		{Name: "admin/metadata-expiry", Destructive: true, Run: checkMetadataExpiry},
+		{Name: "admin/metadata-watch-reset", Destructive: true, Run: checkMetadataWatchReset},
+	}
+}
+
+// createProject creates a project that is deleted when the check finishes.
+func (h *Harness) createProject(ctx context.Context) (*client.Project, error) {
+	project, err := h.Client.CreateProject(ctx, client.CreateProjectRequest{Name: h.Name("project")})
+	if err != nil {
+		return nil, fmt.Errorf("creating project: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteProject(ctx, project.ID) })
+	return project, nil
+}
+
+// createInstance creates an instance in a new project; both are deleted when the check
+// finishes.
+func (h *Harness) createInstance(ctx context.Context) (*client.Instance, error) {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := h.Client.CreateInstance(ctx, client.CreateInstanceRequest{
+		ProjectID: project.ID,
+		Name:      h.Name("instance"),
+		CPU:       1,
+		MemoryMB:  256,
+		Image:     "ubuntu:22.04",
+		Status:    "running",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating instance: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteInstance(ctx, instance.ID) })
+	return instance, nil
+}
+
+// createMetadata creates metadata that is deleted when the check finishes.
+func (h *Harness) createMetadata(ctx context.Context, value string) (*client.Metadata, error) {
+	metadata, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: h.Name("metadata") + "/key", Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("creating metadata: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteMetadata(ctx, metadata.ID) })
+	return metadata, nil
+}
+
+// createBucket creates a bucket that is deleted when the check finishes.
+func (h *Harness) createBucket(ctx context.Context) (*client.Bucket, error) {
+	bucket, err := h.Client.CreateBucket(ctx, client.CreateBucketRequest{Name: h.Name("bucket")})
+	if err != nil {
+		return nil, fmt.Errorf("creating bucket: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteBucket(ctx, bucket.ID) })
+	return bucket, nil
+}
+
+// createObject creates an object in a new bucket; both are deleted when the check
+// finishes.
+func (h *Harness) createObject(ctx context.Context, path string) (*client.Object, error) {
+	bucket, err := h.createBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := h.Client.CreateObject(ctx, bucket.ID, client.CreateObjectRequest{Path: path, Content: "aGVsbG8="})
+	if err != nil {
+		return nil, fmt.Errorf("creating object: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteObject(ctx, bucket.ID, object.ID) })
+	return object, nil
+}
+
+// expectTimestamps fails unless created and updated are set and updated is not earlier.
+func expectTimestamps(what string, created, updated time.Time) error {
+	if created.IsZero() || updated.IsZero() {
+		return fmt.Errorf("%s: created_at and updated_at must be set, got %s and %s", what, created, updated)
+	}
+	if updated.Before(created) {
+		return fmt.Errorf("%s: updated_at %s is before created_at %s", what, updated, created)
+	}
+	return nil
+}
+
+func checkProjectCRUD(ctx context.Context, h *Harness) error {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+	if project.ID == "" {
+		return fmt.Errorf("create project: response has no id")
+	}
+	if err := expectTimestamps("create project", project.CreatedAt, project.UpdatedAt); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetProject(ctx, project.ID)
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	if err := expectEqual("get project", *got, *project); err != nil {
+		return err
+	}
+
+	list, err := h.Client.ListProjects(ctx, project.Name)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	if err := expectEqual("list projects by name", list, []client.Project{*project}); err != nil {
+		return err
+	}
+
+	renamed := h.Name("project")
+	updated, err := h.Client.UpdateProject(ctx, project.ID, client.UpdateProjectRequest{Name: renamed})
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	if err := expectEqual("updated project name", updated.Name, renamed); err != nil {
+		return err
+	}
+	if err := expectEqual("updated project created_at", updated.CreatedAt, project.CreatedAt); err != nil {
+		return err
+	}
+	if err := expectTimestamps("update project", updated.CreatedAt, updated.UpdatedAt); err != nil {
+		return err
+	}
+
+	if err := h.Client.DeleteProject(ctx, project.ID); err != nil {
+		return fmt.Errorf("delete project (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetProject(ctx, project.ID)
+	return expectNotFound("get deleted project", err)
+}
+
+func checkProjectNotFound(ctx context.Context, h *Harness) error {
+	_, err := h.Client.GetProject(ctx, missingID)
+	if err := expectNotFound("get missing project", err); err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateProject(ctx, missingID, client.UpdateProjectRequest{Name: h.Name("project")})
+	if err := expectNotFound("update missing project", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("delete missing project", h.Client.DeleteProject(ctx, missingID)); err != nil {
+		return err
+	}
+	return h.expectErrorBody(ctx, http.MethodGet, "/projects/"+missingID, nil, http.StatusNotFound)
+}
+
+func checkProjectDuplicateName(ctx context.Context, h *Harness) error {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := h.Client.CreateProject(ctx, client.CreateProjectRequest{Name: project.Name})
+	if err == nil {
+		h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteProject(ctx, duplicate.ID) })
+	}
+	if err := expectAPIError("create project with duplicate name", err, http.StatusConflict); err != nil {
+		return err
+	}
+
+	other, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateProject(ctx, other.ID, client.UpdateProjectRequest{Name: project.Name})
+	return expectAPIError("rename project to a taken name", err, http.StatusConflict)
+}
+
+func checkProjectDeleteWithInstances(ctx context.Context, h *Harness) error {
+	instance, err := h.createInstance(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.Client.DeleteProject(ctx, instance.ProjectID)
+	return expectAPIError("delete project that still has instances", err, http.StatusConflict)
+}
+
+func checkProjectQuota(ctx context.Context, h *Harness) error {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+
+	quota, err := h.Client.GetProjectQuota(ctx, project.ID)
+	if err != nil && isNotFound(err) {
+		return skip("server has no quota API")
+	}
+	if err != nil {
+		return fmt.Errorf("get project quota: %w", err)
+	}
+	if err := expectEqual("quota project_id", quota.ProjectID, project.ID); err != nil {
+		return err
+	}
+	return expectEqual("usage of an empty project", quota.Usage, client.ResourceAmounts{})
+}
+
+func checkInstanceCRUD(ctx context.Context, h *Harness) error {
+	instance, err := h.createInstance(ctx)
+	if err != nil {
+		return err
+	}
+	if instance.ID == "" {
+		return fmt.Errorf("create instance: response has no id")
+	}
+	if err := expectTimestamps("create instance", instance.CreatedAt, instance.UpdatedAt); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetInstance(ctx, instance.ID)
+	if err != nil {
+		return fmt.Errorf("get instance: %w", err)
+	}
+	if err := expectEqual("get instance", *got, *instance); err != nil {
+		return err
+	}
+
+	list, err := h.Client.ListInstances(ctx, instance.ProjectID, instance.Name, instance.Status)
+	if err != nil {
+		return fmt.Errorf("list instances: %w", err)
+	}
+	if err := expectEqual("list instances by project, name and status", list, []client.Instance{*instance}); err != nil {
+		return err
+	}
+
+	list, err = h.Client.ListInstances(ctx, instance.ProjectID, "", "stopped")
+	if err != nil {
+		return fmt.Errorf("list instances: %w", err)
+	}
+	if err := expectEqual("list stopped instances of project", len(list), 0); err != nil {
+		return err
+	}
+
+	status := "stopped"
+	updated, err := h.Client.UpdateInstance(ctx, instance.ID, client.UpdateInstanceRequest{Status: &status})
+	if err != nil {
+		return fmt.Errorf("update instance: %w", err)
+	}
+	if err := expectEqual("updated instance status", updated.Status, status); err != nil {
+		return err
+	}
+
+	if err := h.Client.DeleteInstance(ctx, instance.ID); err != nil {
+		return fmt.Errorf("delete instance (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetInstance(ctx, instance.ID)
+	return expectNotFound("get deleted instance", err)
+}
+
+func checkInstancePartialUpdate(ctx context.Context, h *Harness) error {
+	instance, err := h.createInstance(ctx)
+	if err != nil {
+		return err
+	}
+
+	cpu := instance.CPU + 1
+	updated, err := h.Client.UpdateInstance(ctx, instance.ID, client.UpdateInstanceRequest{CPU: &cpu})
+	if err != nil {
+		return fmt.Errorf("update instance cpu: %w", err)
+	}
+
+	want := *instance
+	want.CPU = cpu
+	want.UpdatedAt = updated.UpdatedAt
+	if err := expectEqual("instance after PATCH of cpu only", *updated, want); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetInstance(ctx, instance.ID)
+	if err != nil {
+		return fmt.Errorf("get instance: %w", err)
+	}
+	return expectEqual("instance read after PATCH", *got, *updated)
+}
+
+func checkInstanceNotFound(ctx context.Context, h *Harness) error {
+	_, err := h.Client.GetInstance(ctx, missingID)
+	if err := expectNotFound("get missing instance", err); err != nil {
+		return err
+	}
+	name := h.Name("instance")
+	_, err = h.Client.UpdateInstance(ctx, missingID, client.UpdateInstanceRequest{Name: &name})
+	if err := expectNotFound("update missing instance", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("delete missing instance", h.Client.DeleteInstance(ctx, missingID)); err != nil {
+		return err
+	}
+	return h.expectErrorBody(ctx, http.MethodGet, "/instances/"+missingID, nil, http.StatusNotFound)
+}
+
+func checkInstanceUnknownProject(ctx context.Context, h *Harness) error {
+	instance, err := h.Client.CreateInstance(ctx, client.CreateInstanceRequest{
+		ProjectID: missingID,
+		Name:      h.Name("instance"),
+		CPU:       1,
+		MemoryMB:  256,
+		Image:     "ubuntu:22.04",
+	})
+	if err == nil {
+		h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteInstance(ctx, instance.ID) })
+	}
+	return expectAPIError("create instance in missing project", err, http.StatusBadRequest)
+}
+
+func checkInstanceImmutableImage(ctx context.Context, h *Harness) error {
+	instance, err := h.createInstance(ctx)
+	if err != nil {
+		return err
+	}
+
+	image := "debian:12"
+	_, err = h.Client.UpdateInstance(ctx, instance.ID, client.UpdateInstanceRequest{Image: &image})
+	if err := expectAPIError("change instance image", err, http.StatusBadRequest); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetInstance(ctx, instance.ID)
+	if err != nil {
+		return fmt.Errorf("get instance: %w", err)
+	}
+	return expectEqual("instance after rejected PATCH", *got, *instance)
+}
+
+func checkMetadataCRUD(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "v1")
+	if err != nil {
+		return err
+	}
+	if metadata.ID == "" {
+		return fmt.Errorf("create metadata: response has no id")
+	}
+	if err := expectTimestamps("create metadata", metadata.CreatedAt, metadata.UpdatedAt); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetMetadata(ctx, metadata.ID)
+	if err != nil {
+		return fmt.Errorf("get metadata: %w", err)
+	}
+	if err := expectEqual("get metadata", *got, *metadata); err != nil {
+		return err
+	}
+
+	byPath, err := h.Client.GetMetadataByPath(ctx, metadata.Path)
+	if err != nil {
+		return fmt.Errorf("get metadata by path: %w", err)
+	}
+	if err := expectEqual("get metadata by path", *byPath, *metadata); err != nil {
+		return err
+	}
+
+	list, err := h.Client.ListMetadata(ctx, metadata.Path[:len(metadata.Path)-len("key")])
+	if err != nil {
+		return fmt.Errorf("list metadata: %w", err)
+	}
+	if err := expectEqual("list metadata by prefix", list, []client.Metadata{*metadata}); err != nil {
+		return err
+	}
+
+	value := "v2"
+	updated, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value})
+	if err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+	if err := expectEqual("updated metadata value", updated.Value, value); err != nil {
+		return err
+	}
+
+	if err := h.Client.DeleteMetadata(ctx, metadata.ID); err != nil {
+		return fmt.Errorf("delete metadata (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetMetadata(ctx, metadata.ID)
+	return expectNotFound("get deleted metadata", err)
+}
+
+func checkMetadataPartialUpdate(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "v1")
+	if err != nil {
+		return err
+	}
+
+	value := "v2"
+	updated, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value})
+	if err != nil {
+		return fmt.Errorf("update metadata value: %w", err)
+	}
+	if err := expectEqual("metadata path after PATCH of value only", updated.Path, metadata.Path); err != nil {
+		return err
+	}
+
+	path := h.Name("metadata") + "/key"
+	updated, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Path: &path})
+	if err != nil {
+		return fmt.Errorf("update metadata path: %w", err)
+	}
+	if err := expectEqual("metadata value after PATCH of path only", updated.Value, value); err != nil {
+		return err
+	}
+	return expectEqual("updated metadata path", updated.Path, path)
+}
+
+func checkMetadataSecret(ctx context.Context, h *Harness) error {
+	metadata, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: h.Name("metadata") + "/secret", Value: "s1", Secret: true})
+	if err != nil {
+		return fmt.Errorf("create secret metadata: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteMetadata(ctx, metadata.ID) })
+	if err := expectEqual("created metadata secret", metadata.Secret, true); err != nil {
+		return err
+	}
+	if err := expectEqual("created metadata value_sha256", metadata.ValueSHA256, sha256Hex("s1")); err != nil {
+		return err
+	}
+
+	value := "s2"
+	updated, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value})
+	if err != nil {
+		return fmt.Errorf("update secret metadata value: %w", err)
+	}
+	if err := expectEqual("metadata secret after PATCH of value only", updated.Secret, true); err != nil {
+		return err
+	}
+	if err := expectEqual("updated metadata value_sha256", updated.ValueSHA256, sha256Hex(value)); err != nil {
+		return err
+	}
+
+	secret := false
+	updated, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Secret: &secret})
+	if err != nil {
+		return fmt.Errorf("update metadata secret: %w", err)
+	}
+	if err := expectEqual("metadata value after PATCH of secret only", updated.Value, value); err != nil {
+		return err
+	}
+	return expectEqual("updated metadata secret", updated.Secret, false)
+}
+
+func checkMetadataVersions(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "v1")
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("created metadata version", metadata.Version, 1); err != nil {
+		return err
+	}
+
+	value := "v2"
+	updated, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value})
+	if err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+	if err := expectEqual("updated metadata version", updated.Version, 2); err != nil {
+		return err
+	}
+
+	versions, err := h.Client.ListMetadataVersions(ctx, metadata.ID)
+	if err != nil {
+		return fmt.Errorf("list metadata versions: %w", err)
+	}
+	values := []string{}
+	for _, v := range versions {
+		values = append(values, fmt.Sprintf("%d=%s", v.Version, v.Value))
+	}
+	if err := expectEqual("metadata versions", values, []string{"1=v1", "2=v2"}); err != nil {
+		return err
+	}
+
+	first, err := h.Client.GetMetadataVersion(ctx, metadata.ID, 1)
+	if err != nil {
+		return fmt.Errorf("get metadata version 1: %w", err)
+	}
+	if err := expectEqual("metadata version 1", *first, versions[0]); err != nil {
+		return err
+	}
+
+	_, err = h.Client.GetMetadataVersion(ctx, metadata.ID, 3)
+	if err := expectNotFound("get missing metadata version", err); err != nil {
+		return err
+	}
+	_, err = h.Client.ListMetadataVersions(ctx, missingID)
+	return expectNotFound("list versions of missing metadata", err)
+}
+
+func checkMetadataCompareAndSwap(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "1")
+	if err != nil {
+		return err
+	}
+
+	_, err = h.Client.CreateMetadataIfAbsent(ctx, client.CreateMetadataRequest{Path: metadata.Path, Value: "0"})
+	if err := expectConflict("create metadata at a taken path", err, http.StatusConflict); err != nil {
+		return err
+	}
+
+	value, version := "2", 1
+	swapped, err := h.Client.CompareAndSwapMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value, IfVersion: &version})
+	if err != nil {
+		return fmt.Errorf("compare-and-swap on the current version: %w", err)
+	}
+	if err := expectEqual("metadata after compare-and-swap", []interface{}{swapped.Value, swapped.Version}, []interface{}{"2", 2}); err != nil {
+		return err
+	}
+
+	value = "3"
+	_, err = h.Client.CompareAndSwapMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value, IfVersion: &version})
+	if err := expectConflict("compare-and-swap on a stale version", err, http.StatusPreconditionFailed); err != nil {
+		return err
+	}
+	stale := "1"
+	_, err = h.Client.CompareAndSwapMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value, IfValue: &stale})
+	if err := expectConflict("compare-and-swap on a stale value", err, http.StatusPreconditionFailed); err != nil {
+		return err
+	}
+	current := "2"
+	if _, err := h.Client.CompareAndSwapMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value, IfValue: &current}); err != nil {
+		return fmt.Errorf("compare-and-swap on the current value: %w", err)
+	}
+
+	err = h.Client.DeleteMetadataIfVersion(ctx, metadata.ID, 2)
+	if err := expectConflict("delete metadata at a stale version", err, http.StatusPreconditionFailed); err != nil {
+		return err
+	}
+	if err := h.Client.DeleteMetadataIfVersion(ctx, metadata.ID, 3); err != nil {
+		return fmt.Errorf("delete metadata at the current version (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetMetadata(ctx, metadata.ID)
+	return expectNotFound("get conditionally deleted metadata", err)
+}
+
+func checkMetadataWatch(ctx context.Context, h *Harness) error {
+	prefix := h.Name("metadata") + "/"
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	watch, err := h.Client.WatchMetadata(watchCtx, prefix, 0)
+	if err != nil {
+		return fmt.Errorf("watch metadata (expected HTTP 200): %w", err)
+	}
+	start := watch.Revision()
+
+	// Changes to other paths are not streamed
+	if _, err := h.createMetadata(ctx, "other"); err != nil {
+		return err
+	}
+	metadata, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: prefix + "key", Value: "1"})
+	if err != nil {
+		return fmt.Errorf("create metadata: %w", err)
+	}
+	h.Cleanup(func(ctx context.Context) error {
+		if err := h.Client.DeleteMetadata(ctx, metadata.ID); err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	})
+	value := "2"
+	if _, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value}); err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+	if err := h.Client.DeleteMetadata(ctx, metadata.ID); err != nil {
+		return fmt.Errorf("delete metadata: %w", err)
+	}
+
+	want := []string{"create " + metadata.Path + " 1", "update " + metadata.Path + " 2", "delete " + metadata.Path + " 2"}
+	var revisions []int64
+	for _, w := range want {
+		e, err := nextMetadataEvent("watched "+w, watch)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("watched event", describeMetadataEvent(e), w); err != nil {
+			return err
+		}
+		if n := len(revisions); e.Revision <= start || (n > 0 && e.Revision <= revisions[n-1]) {
+			return fmt.Errorf("watched %s: revision %d does not follow %d and %v", w, e.Revision, start, revisions)
+		}
+		revisions = append(revisions, e.Revision)
+	}
+
+	// Resuming replays the same changes
+	resumed, err := h.Client.WatchMetadata(watchCtx, prefix, start)
+	if err != nil {
+		return fmt.Errorf("resume metadata watch at revision %d (expected HTTP 200): %w", start, err)
+	}
+	for i, w := range want {
+		e, err := nextMetadataEvent("resumed "+w, resumed)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("resumed event", []interface{}{e.Revision, describeMetadataEvent(e)}, []interface{}{revisions[i], w}); err != nil {
+			return err
+		}
+	}
+
+	_, err = h.Client.WatchMetadata(watchCtx, prefix, revisions[len(revisions)-1]+1000)
+	return expectAPIError("watch metadata from a future revision", err, http.StatusGone)
+}
+
+// nextMetadataEvent returns the next event of watch, failing if none arrives in time.
+func nextMetadataEvent(what string, watch *client.MetadataWatch) (client.MetadataEvent, error) {
+	select {
+	case e, ok := <-watch.Events():
+		if !ok {
+			return e, fmt.Errorf("%s: watch ended: %v", what, watch.Err())
+		}
+		return e, nil
+	case <-time.After(10 * time.Second):
+		return client.MetadataEvent{}, fmt.Errorf("%s: no event within 10s", what)
+	}
+}
+
+// describeMetadataEvent summarizes e as its type, path and value.
+func describeMetadataEvent(e client.MetadataEvent) string {
+	if e.Metadata == nil {
+		return string(e.Type)
+	}
+	return fmt.Sprintf("%s %s %s", e.Type, e.Metadata.Path, e.Metadata.Value)
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest the server reports for value.
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func checkMetadataNotFound(ctx context.Context, h *Harness) error {
+	_, err := h.Client.GetMetadata(ctx, missingID)
+	if err := expectNotFound("get missing metadata", err); err != nil {
+		return err
+	}
+	_, err = h.Client.GetMetadataByPath(ctx, h.Name("metadata"))
+	if err := expectNotFound("get metadata by missing path", err); err != nil {
+		return err
+	}
+	value := "v"
+	_, err = h.Client.UpdateMetadata(ctx, missingID, client.UpdateMetadataRequest{Value: &value})
+	if err := expectNotFound("update missing metadata", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("delete missing metadata", h.Client.DeleteMetadata(ctx, missingID)); err != nil {
+		return err
+	}
+	return h.expectErrorBody(ctx, http.MethodGet, "/metadata/"+missingID, nil, http.StatusNotFound)
+}
+
+func checkMetadataDuplicatePath(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "v1")
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: metadata.Path, Value: "v2"})
+	if err == nil {
+		h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteMetadata(ctx, duplicate.ID) })
+	}
+	if err := expectAPIError("create metadata with duplicate path", err, http.StatusConflict); err != nil {
+		return err
+	}
+
+	other, err := h.createMetadata(ctx, "v3")
+	if err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateMetadata(ctx, other.ID, client.UpdateMetadataRequest{Path: &metadata.Path})
+	return expectAPIError("move metadata to a taken path", err, http.StatusConflict)
+}
+
+func checkMetadataInvalidPath(ctx context.Context, h *Harness) error {
+	base := h.Name("metadata")
+	invalid := []string{"/" + base, base + "/", base + "//key", base + "/../key", base + "/my key", strings.Repeat(base+"/", client.MaxMetadataPathDepth) + "key"}
+	for _, path := range invalid {
+		created, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path, Value: "v"})
+		if err == nil {
+			h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteMetadata(ctx, created.ID) })
+		}
+		if err := expectAPIError(fmt.Sprintf("create metadata with path %q", path), err, http.StatusBadRequest); err != nil {
+			return err
+		}
+	}
+
+	metadata, err := h.createMetadata(ctx, "v")
+	if err != nil {
+		return err
+	}
+	path := "/" + metadata.Path
+	_, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Path: &path})
+	return expectAPIError("move metadata to an invalid path", err, http.StatusBadRequest)
+}
+
+func checkBucketCRUD(ctx context.Context, h *Harness) error {
+	bucket, err := h.createBucket(ctx)
+	if err != nil {
+		return err
+	}
+	if bucket.ID == "" {
+		return fmt.Errorf("create bucket: response has no id")
+	}
+	if err := expectTimestamps("create bucket", bucket.CreatedAt, bucket.UpdatedAt); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetBucket(ctx, bucket.ID)
+	if err != nil {
+		return fmt.Errorf("get bucket: %w", err)
+	}
+	if err := expectEqual("get bucket", *got, *bucket); err != nil {
+		return err
+	}
+
+	list, err := h.Client.ListBuckets(ctx, bucket.Name)
+	if err != nil {
+		return fmt.Errorf("list buckets: %w", err)
+	}
+	if err := expectEqual("list buckets by name", list, []client.Bucket{*bucket}); err != nil {
+		return err
+	}
+
+	renamed := h.Name("bucket")
+	updated, err := h.Client.UpdateBucket(ctx, bucket.ID, client.UpdateBucketRequest{Name: renamed})
+	if err != nil {
+		return fmt.Errorf("update bucket: %w", err)
+	}
+	if err := expectEqual("updated bucket name", updated.Name, renamed); err != nil {
+		return err
+	}
+
+	if err := h.Client.DeleteBucket(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("delete bucket (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetBucket(ctx, bucket.ID)
+	return expectNotFound("get deleted bucket", err)
+}
+
+func checkBucketNotFound(ctx context.Context, h *Harness) error {
+	_, err := h.Client.GetBucket(ctx, missingID)
+	if err := expectNotFound("get missing bucket", err); err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateBucket(ctx, missingID, client.UpdateBucketRequest{Name: h.Name("bucket")})
+	if err := expectNotFound("update missing bucket", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("delete missing bucket", h.Client.DeleteBucket(ctx, missingID)); err != nil {
+		return err
+	}
+	return h.expectErrorBody(ctx, http.MethodGet, "/buckets/"+missingID, nil, http.StatusNotFound)
+}
+
+func checkBucketDuplicateName(ctx context.Context, h *Harness) error {
+	bucket, err := h.createBucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := h.Client.CreateBucket(ctx, client.CreateBucketRequest{Name: bucket.Name})
+	if err == nil {
+		h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteBucket(ctx, duplicate.ID) })
+	}
+	if err := expectAPIError("create bucket with duplicate name", err, http.StatusConflict); err != nil {
+		return err
+	}
+
+	other, err := h.createBucket(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateBucket(ctx, other.ID, client.UpdateBucketRequest{Name: bucket.Name})
+	return expectAPIError("rename bucket to a taken name", err, http.StatusConflict)
+}
+
+func checkObjectCRUD(ctx context.Context, h *Harness) error {
+	object, err := h.createObject(ctx, "docs/readme.txt")
+	if err != nil {
+		return err
+	}
+	if object.ID == "" {
+		return fmt.Errorf("create object: response has no id")
+	}
+	if err := expectTimestamps("create object", object.CreatedAt, object.UpdatedAt); err != nil {
+		return err
+	}
+
+	got, err := h.Client.GetObject(ctx, object.BucketID, object.ID)
+	if err != nil {
+		return fmt.Errorf("get object: %w", err)
+	}
+	if err := expectEqual("get object", *got, *object); err != nil {
+		return err
+	}
+
+	list, err := h.Client.ListObjects(ctx, object.BucketID)
+	if err != nil {
+		return fmt.Errorf("list objects: %w", err)
+	}
+	if err := expectEqual("list objects", list, []client.Object{*object}); err != nil {
+		return err
+	}
+
+	content := "Z29vZGJ5ZQ=="
+	updated, err := h.Client.UpdateObject(ctx, object.BucketID, object.ID, client.UpdateObjectRequest{Content: &content})
+	if err != nil {
+		return fmt.Errorf("update object: %w", err)
+	}
+	if err := expectEqual("updated object content", updated.Content, content); err != nil {
+		return err
+	}
+
+	if err := h.Client.DeleteObject(ctx, object.BucketID, object.ID); err != nil {
+		return fmt.Errorf("delete object (expected HTTP 204): %w", err)
+	}
+	_, err = h.Client.GetObject(ctx, object.BucketID, object.ID)
+	return expectNotFound("get deleted object", err)
+}
+
+func checkObjectPartialUpdate(ctx context.Context, h *Harness) error {
+	object, err := h.createObject(ctx, "a.txt")
+	if err != nil {
+		return err
+	}
+
+	path := "b.txt"
+	updated, err := h.Client.UpdateObject(ctx, object.BucketID, object.ID, client.UpdateObjectRequest{Path: &path})
+	if err != nil {
+		return fmt.Errorf("update object path: %w", err)
+	}
+
+	want := *object
+	want.Path = path
+	want.UpdatedAt = updated.UpdatedAt
+	return expectEqual("object after PATCH of path only", *updated, want)
+}
+
+func checkObjectNotFound(ctx context.Context, h *Harness) error {
+	bucket, err := h.createBucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.Client.GetObject(ctx, bucket.ID, missingID)
+	if err := expectNotFound("get missing object", err); err != nil {
+		return err
+	}
+	content := "eA=="
+	_, err = h.Client.UpdateObject(ctx, bucket.ID, missingID, client.UpdateObjectRequest{Content: &content})
+	if err := expectNotFound("update missing object", err); err != nil {
+		return err
+	}
+	if err := expectNotFound("delete missing object", h.Client.DeleteObject(ctx, bucket.ID, missingID)); err != nil {
+		return err
+	}
+
+	_, err = h.Client.ListObjects(ctx, missingID)
+	if err := expectAPIError("list objects of missing bucket", err, http.StatusNotFound); err != nil {
+		return err
+	}
+	return h.expectErrorBody(ctx, http.MethodGet, "/bucket/"+bucket.ID+"/objects/"+missingID, nil, http.StatusNotFound)
+}
+
+func checkObjectDuplicatePath(ctx context.Context, h *Harness) error {
+	object, err := h.createObject(ctx, "a.txt")
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := h.Client.CreateObject(ctx, object.BucketID, client.CreateObjectRequest{Path: object.Path, Content: "eA=="})
+	if err == nil {
+		h.Cleanup(func(ctx context.Context) error { return h.Client.DeleteObject(ctx, object.BucketID, duplicate.ID) })
+	}
+	return expectAPIError("create object with duplicate path", err, http.StatusConflict)
+}
+
+func checkEvents(ctx context.Context, h *Harness) error {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := h.Client.ListEvents(ctx, client.EventFilter{ResourceID: project.ID, Action: "create"})
+	if err != nil && isNotFound(err) {
+		return skip("server has no events API")
+	}
+	if err != nil {
+		return fmt.Errorf("list events: %w", err)
+	}
+	if len(events) != 1 {
+		return fmt.Errorf("list create events of project: got %d events, want 1", len(events))
+	}
+
+	event := events[0]
+	if err := expectEqual("event resource_type", event.ResourceType, "project"); err != nil {
+		return err
+	}
+	if err := expectEqual("event after.name", event.After["name"], project.Name); err != nil {
+		return err
+	}
+	if event.Before != nil {
+		return fmt.Errorf("create event: before must be empty, got %v", event.Before)
+	}
+	return nil
+}
+
+func checkSnapshot(ctx context.Context, h *Harness) error {
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := h.Client.Snapshot(ctx)
+	if err != nil && isNotFound(err) {
+		return skip("server has no admin API")
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := expectEqual("snapshot version", snapshot.Version, client.SnapshotFormatVersion); err != nil {
+		return err
+	}
+	for _, p := range snapshot.Projects {
+		if p.ID == project.ID {
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot does not contain project %s", project.ID)
+}
+
+// restoreAfter snapshots the server and restores the snapshot once the check finishes.
+func (h *Harness) restoreAfter(ctx context.Context) (*client.Snapshot, error) {
+	snapshot, err := h.Client.Snapshot(ctx)
+	if err != nil && isNotFound(err) {
+		return nil, skip("server has no admin API")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+
+	h.Cleanup(func(ctx context.Context) error { return h.Client.RestoreSnapshot(ctx, *snapshot) })
+	return snapshot, nil
+}
+
+func checkResetRestore(ctx context.Context, h *Harness) error {
+	if _, err := h.createProject(ctx); err != nil {
+		return err
+	}
+	original, err := h.restoreAfter(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Client.ResetServer(ctx); err != nil {
+		return fmt.Errorf("reset (expected HTTP 204): %w", err)
+	}
+	projects, err := h.Client.ListProjects(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	if err := expectEqual("projects after reset", len(projects), 0); err != nil {
+		return err
+	}
+
+	if err := h.Client.RestoreSnapshot(ctx, *original); err != nil {
+		return fmt.Errorf("restore (expected HTTP 204): %w", err)
+	}
+	projects, err = h.Client.ListProjects(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	return expectEqual("projects after restore", projects, original.Projects)
+}
+
+func checkDeterministicClock(ctx context.Context, h *Harness) error {
+	if _, err := h.restoreAfter(ctx); err != nil {
+		return err
+	}
+
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := h.Client.EnableDeterministicMode(ctx, client.DeterministicModeRequest{Start: start}); err != nil {
+		return fmt.Errorf("enable deterministic mode (expected HTTP 204): %w", err)
+	}
+
+	project, err := h.createProject(ctx)
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("created_at in deterministic mode", project.CreatedAt, start); err != nil {
+		return err
+	}
+
+	now, err := h.Client.AdvanceClock(ctx, time.Hour)
+	if err != nil {
+		return fmt.Errorf("advance clock: %w", err)
+	}
+	if err := expectEqual("clock after advancing 1h", now, start.Add(time.Hour)); err != nil {
+		return err
+	}
+
+	target := start.Add(24 * time.Hour)
+	now, err = h.Client.SetClock(ctx, target)
+	if err != nil {
+		return fmt.Errorf("set clock: %w", err)
+	}
+	if err := expectEqual("clock after setting it", now, target); err != nil {
+		return err
+	}
+
+	renamed := h.Name("project")
+	updated, err := h.Client.UpdateProject(ctx, project.ID, client.UpdateProjectRequest{Name: renamed})
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	return expectEqual("updated_at after setting the clock", updated.UpdatedAt, target)
+}
+
+func checkMetadataExpiry(ctx context.Context, h *Harness) error {
+	if _, err := h.restoreAfter(ctx); err != nil {
+		return err
+	}
+
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := h.Client.EnableDeterministicMode(ctx, client.DeterministicModeRequest{Start: start}); err != nil {
+		return fmt.Errorf("enable deterministic mode (expected HTTP 204): %w", err)
+	}
+
+	path := h.Name("metadata") + "/key"
+	metadata, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path, Value: "v", TTL: 3600})
+	if err != nil {
+		return fmt.Errorf("create metadata with ttl: %w", err)
+	}
+	expiresAt := start.Add(time.Hour)
+	if err := expectEqual("expires_at after ttl 3600", metadata.ExpiresAt, &expiresAt); err != nil {
+		return err
+	}
+
+	noTTL := 0
+	updated, err := h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{TTL: &noTTL})
+	if err != nil {
+		return fmt.Errorf("update metadata with ttl 0: %w", err)
+	}
+	if err := expectEqual("expires_at after ttl 0", updated.ExpiresAt, (*time.Time)(nil)); err != nil {
+		return err
+	}
+	updated, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{ExpiresAt: &expiresAt})
+	if err != nil {
+		return fmt.Errorf("update metadata with expires_at: %w", err)
+	}
+	if err := expectEqual("expires_at after setting it", updated.ExpiresAt, &expiresAt); err != nil {
+		return err
+	}
+
+	negative := -1
+	_, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{TTL: &negative})
+	if err := expectAPIError("update metadata with negative ttl", err, http.StatusBadRequest); err != nil {
+		return err
+	}
+	_, err = h.Client.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{ExpiresAt: &start})
+	if err := expectAPIError("update metadata with past expires_at", err, http.StatusBadRequest); err != nil {
+		return err
+	}
+
+	if _, err := h.Client.AdvanceClock(ctx, time.Hour); err != nil {
+		return fmt.Errorf("advance clock: %w", err)
+	}
+
+	_, err = h.Client.GetMetadata(ctx, metadata.ID)
+	if err := expectAPIError("get expired metadata", err, http.StatusNotFound); err != nil {
+		return err
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		if err := expectEqual("error code of expired metadata", apiErr.Code, "expired"); err != nil {
+			return err
+		}
+	}
+	_, err = h.Client.GetMetadataByPath(ctx, path)
+	if err := expectNotFound("get expired metadata by path", err); err != nil {
+		return err
+	}
+
+	// The path of expired metadata can be reused right away
+	recreated, err := h.Client.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path, Value: "v"})
+	if err != nil {
+		return fmt.Errorf("create metadata at expired path: %w", err)
+	}
+	if err := expectEqual("recreated metadata version", recreated.Version, 1); err != nil {
+		return err
+	}
+	_, err = h.Client.GetMetadata(ctx, metadata.ID)
+	return expectNotFound("get replaced expired metadata", err)
+}
+
+func checkMetadataWatchReset(ctx context.Context, h *Harness) error {
+	metadata, err := h.createMetadata(ctx, "v")
+	if err != nil {
+		return err
+	}
+	original, err := h.restoreAfter(ctx)
+	if err != nil {
+		return err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watch, err := h.Client.WatchMetadata(watchCtx, metadata.Path, 0)
+	if err != nil {
+		return fmt.Errorf("watch metadata (expected HTTP 200): %w", err)
+	}
+	start := watch.Revision()
+
+	// Resets are streamed whatever the prefix, and a restore recreates the metadata
+	if err := h.Client.ResetServer(ctx); err != nil {
+		return fmt.Errorf("reset (expected HTTP 204): %w", err)
+	}
+	if err := h.Client.RestoreSnapshot(ctx, *original); err != nil {
+		return fmt.Errorf("restore (expected HTTP 204): %w", err)
+	}
+
+	revision := start
+	for _, w := range []string{"reset", "reset", "create " + metadata.Path + " v"} {
+		e, err := nextMetadataEvent("watched "+w, watch)
+		if err != nil {
+			return err
+		}
+		if err := expectEqual("watched event", describeMetadataEvent(e), w); err != nil {
+			return err
+		}
+		if e.Revision <= revision {
+			return fmt.Errorf("watched %s: revision %d is not after %d", w, e.Revision, revision)
+		}
+		revision = e.Revision
+	}
+	return nil
+}
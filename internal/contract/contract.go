@@ -0,0 +1,312 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package contract is a conformance suite for DirtCloud API servers. It exercises every
+// client.Client method against a live server, together with the edge cases where
+// implementations tend to disagree (status codes, error bodies, PATCH semantics), and
+// summarizes the outcome in a report.
+package contract
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Prefix starts the name of everything the suite creates, so the acceptance test
+// sweepers remove whatever an interrupted run leaves behind.
+const Prefix = "tf-acc-contract-"
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Check is a single conformance check.
+type Check struct {
+	// Name identifies the check in reports, e.g. "projects/duplicate-name".
+	Name string
+	// Destructive checks replace or reset the whole server state. They restore it
+	// afterwards, but are only run when Options.Destructive is set.
+	Destructive bool
+	// Run performs the check, returning an error describing the first deviation.
+	Run func(ctx context.Context, h *Harness) error
+}
+
+// Result is the outcome of running a Check.
+type Result struct {
+	Name     string
+	Status   Status
+	Message  string
+	Duration time.Duration
+}
+
+// Options configures a Harness.
+type Options struct {
+	// Destructive enables checks that reset the server or switch it to deterministic mode.
+	// Never enable it against a server whose IDs or clock others rely on.
+	Destructive bool
+}
+
+// Harness runs checks against a server.
+type Harness struct {
+	// Client talks to the server under test.
+	Client *client.Client
+
+	opts     Options
+	suffix   string
+	names    int
+	cleanups []func(ctx context.Context) error
+}
+
+// New returns a Harness for the server behind c.
+func New(c *client.Client, opts Options) *Harness {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return &Harness{Client: c, opts: opts, suffix: hex.EncodeToString(b)}
+}
+
+// Name returns a unique name for a resource of the given kind created by a check.
+func (h *Harness) Name(kind string) string {
+	h.names++
+	return fmt.Sprintf("%s%s-%s-%d", Prefix, kind, h.suffix, h.names)
+}
+
+// Cleanup registers f to undo a change once the running check finishes. Cleanups run in
+// reverse order of registration; "not found" errors are ignored.
+func (h *Harness) Cleanup(f func(ctx context.Context) error) {
+	h.cleanups = append(h.cleanups, f)
+}
+
+// Run runs check and returns its result, undoing whatever the check created.
+func (h *Harness) Run(ctx context.Context, check Check) Result {
+	result := Result{Name: check.Name}
+
+	if check.Destructive && !h.opts.Destructive {
+		result.Status = StatusSkip
+		result.Message = "destructive checks are disabled"
+		return result
+	}
+
+	start := time.Now()
+	err := check.Run(ctx, h)
+
+	var cleanupErrs []error
+	for i := len(h.cleanups) - 1; i >= 0; i-- {
+		if cerr := h.cleanups[i](ctx); cerr != nil && !isNotFound(cerr) {
+			cleanupErrs = append(cleanupErrs, cerr)
+		}
+	}
+	h.cleanups = nil
+	result.Duration = time.Since(start)
+
+	var skip skipError
+	switch {
+	case errors.As(err, &skip):
+		result.Status = StatusSkip
+		result.Message = string(skip)
+	case err != nil:
+		result.Status = StatusFail
+		result.Message = err.Error()
+	case len(cleanupErrs) > 0:
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("cleanup: %s", errors.Join(cleanupErrs...))
+	default:
+		result.Status = StatusPass
+	}
+
+	return result
+}
+
+// RunAll runs every check in Checks against the server behind c.
+func RunAll(ctx context.Context, c *client.Client, opts Options) *Report {
+	h := New(c, opts)
+	report := &Report{Endpoint: c.BaseURL}
+
+	for _, check := range Checks() {
+		report.Add(h.Run(ctx, check))
+	}
+
+	return report
+}
+
+// Report collects the results of a conformance run.
+type Report struct {
+	Endpoint string
+	Results  []Result
+}
+
+// Add appends a result to the report.
+func (r *Report) Add(result Result) {
+	r.Results = append(r.Results, result)
+}
+
+// Count returns the number of results with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes the report as a human-readable table.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "DirtCloud API conformance report for %s\n\n", r.Endpoint)
+
+	width := 0
+	for _, result := range r.Results {
+		width = max(width, len(result.Name))
+	}
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "%s  %-*s  %6s", result.Status, width, result.Name, result.Duration.Round(time.Millisecond))
+		if result.Message != "" {
+			fmt.Fprintf(&b, "  %s", result.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\n%d passed, %d failed, %d skipped\n", r.Count(StatusPass), r.Count(StatusFail), r.Count(StatusSkip))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// skipError reports that a check does not apply to the server under test.
+type skipError string
+
+func (e skipError) Error() string { return string(e) }
+
+// skip returns an error that marks the running check as skipped.
+func skip(format string, args ...interface{}) error {
+	return skipError(fmt.Sprintf(format, args...))
+}
+
+// isNotFound reports whether err is the client's error for a missing resource.
+func isNotFound(err error) bool {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return err != nil && strings.HasSuffix(err.Error(), "not found")
+}
+
+// expectNotFound fails unless err reports a missing resource.
+func expectNotFound(what string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s: expected HTTP 404, got success", what)
+	}
+	if !isNotFound(err) {
+		return fmt.Errorf("%s: expected HTTP 404, got %s", what, err)
+	}
+	return nil
+}
+
+// expectAPIError fails unless err is an API error with the given status and a JSON
+// ErrorResponse body.
+func expectAPIError(what string, err error, status int) error {
+	if err == nil {
+		return fmt.Errorf("%s: expected HTTP %d, got success", what, status)
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("%s: expected HTTP %d, got %s", what, status, err)
+	}
+	if apiErr.StatusCode != status {
+		return fmt.Errorf("%s: expected HTTP %d, got %s", what, status, err)
+	}
+	if apiErr.Code == "" {
+		return fmt.Errorf("%s: HTTP %d response is not a JSON error body with an \"error\" code", what, status)
+	}
+	return nil
+}
+
+// expectConflict fails unless err is a *client.ConflictError with the given status.
+func expectConflict(what string, err error, status int) error {
+	if err := expectAPIError(what, err, status); err != nil {
+		return err
+	}
+	var conflict *client.ConflictError
+	if !errors.As(err, &conflict) {
+		return fmt.Errorf("%s: expected a conflict error, got %T", what, err)
+	}
+	return nil
+}
+
+// expectEqual fails unless got and want are deeply equal.
+func expectEqual(what string, got, want interface{}) error {
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%s: got %+v, want %+v", what, got, want)
+	}
+	return nil
+}
+
+// expectErrorBody sends a raw request and fails unless the server answers with status
+// and a JSON error body carrying both an error code and a message.
+func (h *Harness) expectErrorBody(ctx context.Context, method, path string, body interface{}, status int) error {
+	what := method + " " + path
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.Client.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if h.Client.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Client.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := h.Client.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != status {
+		return fmt.Errorf("%s: expected HTTP %d, got HTTP %d", what, status, resp.StatusCode)
+	}
+
+	var errResp client.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		return fmt.Errorf("%s: error body is not JSON: %s", what, err)
+	}
+	if errResp.Error == "" || errResp.Message == "" {
+		return fmt.Errorf("%s: error body must have non-empty \"error\" and \"message\", got %+v", what, errResp)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		return fmt.Errorf("%s: error Content-Type is %q, want application/json", what, ct)
+	}
+
+	return nil
+}
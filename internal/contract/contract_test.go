@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package contract
+
+import (
+	"context"
+	"flag"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/terraform-provider-dirt/internal/client"
+	"github.com/terraform-provider-dirt/internal/server"
+)
+
+var (
+	endpoint = flag.String("dirt.endpoint", "",
+		"base URL of the DirtCloud API to check, e.g. http://localhost:8080/v1; the bundled stand-in server is started in-process when empty")
+	destructive = flag.Bool("dirt.destructive", false,
+		"also run checks that reset the server and switch it to deterministic mode; always on for the in-process server")
+)
+
+// TestContract runs the conformance suite, one subtest per check, and logs the report.
+// Authentication uses DIRT_TOKEN.
+func TestContract(t *testing.T) {
+	c := client.NewClient(*endpoint)
+	opts := Options{Destructive: *destructive}
+
+	if *endpoint == "" {
+		srv := httptest.NewServer(server.New(server.Options{}))
+		t.Cleanup(srv.Close)
+
+		c = client.NewClient(srv.URL + "/v1")
+		c.Token = ""
+		opts.Destructive = true
+	}
+
+	ctx := context.Background()
+	h := New(c, opts)
+	report := &Report{Endpoint: c.BaseURL}
+
+	for _, check := range Checks() {
+		t.Run(check.Name, func(t *testing.T) {
+			result := h.Run(ctx, check)
+			report.Add(result)
+
+			switch result.Status {
+			case StatusFail:
+				t.Error(result.Message)
+			case StatusSkip:
+				t.Skip(result.Message)
+			}
+		})
+	}
+
+	var b strings.Builder
+	if err := report.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + b.String())
+}
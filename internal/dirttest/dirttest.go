@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package dirttest provides helpers for tests that run against a DirtCloud server.
+package dirttest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Client returns a client for an external server under test, configured from
+// DIRT_ENDPOINT and DIRT_TOKEN like the provider itself.
+func Client() *client.Client {
+	return client.NewClient(os.Getenv("DIRT_ENDPOINT"))
+}
+
+// LoadSnapshot reads a snapshot document from file.
+func LoadSnapshot(t testing.TB, file string) client.Snapshot {
+	t.Helper()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading snapshot %s: %s", file, err)
+	}
+
+	var snapshot client.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("decoding snapshot %s: %s", file, err)
+	}
+
+	return snapshot
+}
+
+// WithSnapshot restores the snapshot fixture in file on the server c talks to, and resets
+// the server once the test and its subtests have finished.
+func WithSnapshot(t testing.TB, c *client.Client, file string) {
+	t.Helper()
+
+	snapshot := LoadSnapshot(t, file)
+
+	if err := c.RestoreSnapshot(context.Background(), snapshot); err != nil {
+		t.Fatalf("restoring snapshot %s: %s", file, err)
+	}
+
+	t.Cleanup(func() {
+		if err := c.ResetServer(context.Background()); err != nil {
+			t.Errorf("resetting server after test: %s", err)
+		}
+	})
+}
+
+// SaveSnapshot captures the state of the server c talks to into file, e.g. to record a
+// golden environment that later tests restore with WithSnapshot.
+func SaveSnapshot(t testing.TB, c *client.Client, file string) {
+	t.Helper()
+
+	snapshot, err := c.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("capturing snapshot: %s", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		t.Fatalf("encoding snapshot: %s", err)
+	}
+
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		t.Fatalf("writing snapshot %s: %s", file, err)
+	}
+}
+
+// Deterministic switches the server c talks to to sequential IDs (proj-0001, ...) and a
+// fake clock, so tests can assert exact IDs and timestamps. The server state is cleared
+// when it is enabled, and reset again once the test has finished.
+func Deterministic(t testing.TB, c *client.Client, seed int) {
+	t.Helper()
+
+	if err := c.EnableDeterministicMode(context.Background(), client.DeterministicModeRequest{Seed: seed}); err != nil {
+		t.Fatalf("enabling deterministic mode: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if err := c.ResetServer(context.Background()); err != nil {
+			t.Errorf("resetting server after test: %s", err)
+		}
+	})
+}
+
+// AdvanceClock moves the fake clock of the server c talks to forward by d. The server
+// must be in deterministic mode.
+func AdvanceClock(t testing.TB, c *client.Client, d time.Duration) time.Time {
+	t.Helper()
+
+	now, err := c.AdvanceClock(context.Background(), d)
+	if err != nil {
+		t.Fatalf("advancing server clock: %s", err)
+	}
+
+	return now
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BucketDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &BucketDataSource{}
+
+func NewBucketDataSource() datasource.DataSource {
+	return &BucketDataSource{}
+}
+
+// BucketDataSource defines the data source implementation.
+type BucketDataSource struct {
+	client *client.Client
+}
+
+// BucketDataSourceModel describes the data source data model.
+type BucketDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Name      types.String `tfsdk:"name"`
+	CreatedAt types.String `tfsdk:"created_at"`
+	UpdatedAt types.String `tfsdk:"updated_at"`
+}
+
+func (d *BucketDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket"
+}
+
+func (d *BucketDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud bucket data source. Looks up an existing bucket by `id` or by `name`; exactly one of them must be set.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Bucket identifier",
+				Optional:            true,
+				Computed:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Bucket name",
+				Optional:            true,
+				Computed:            true,
+			},
+			"created_at": schema.StringAttribute{
+				MarkdownDescription: "Bucket creation timestamp",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Bucket last updated timestamp",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BucketDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("name"),
+		),
+	}
+}
+
+func (d *BucketDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BucketDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BucketDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var bucket *client.Bucket
+
+	if !data.ID.IsNull() {
+		// Get the bucket from the API
+		b, err := d.client.GetBucket(ctx, data.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "read", "bucket", data.ID.ValueString(), err)
+			return
+		}
+		bucket = b
+	} else {
+		name := data.Name.ValueString()
+
+		// Look the bucket up by name
+		buckets, err := d.client.ListBuckets(ctx, name)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "list", "buckets", "", err)
+			return
+		}
+
+		bucket = lookupByName(&resp.Diagnostics, "bucket", name, "", buckets,
+			func(b client.Bucket) string { return b.Name },
+			func(b client.Bucket) string { return b.ID })
+		if bucket == nil {
+			return
+		}
+	}
+
+	// Update the model with the API response data
+	data.ID = types.StringValue(bucket.ID)
+	data.Name = types.StringValue(bucket.Name)
+	data.CreatedAt = types.StringValue(bucket.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	data.UpdatedAt = types.StringValue(bucket.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
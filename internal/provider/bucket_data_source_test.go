@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_bucket" "test" {
+  name = "tf-acc-bucket-ds"
+}
+
+data "dirt_bucket" "by_id" {
+  id = dirt_bucket.test.id
+}
+
+data "dirt_bucket" "by_name" {
+  name = dirt_bucket.test.name
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_bucket.by_id", "id", "dirt_bucket.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_bucket.by_id", "name", "tf-acc-bucket-ds"),
+					resource.TestCheckResourceAttrPair("data.dirt_bucket.by_id", "created_at", "dirt_bucket.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.dirt_bucket.by_id", "updated_at"),
+					resource.TestCheckResourceAttrPair("data.dirt_bucket.by_name", "id", "dirt_bucket.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_bucket.by_name", "name", "tf-acc-bucket-ds"),
+					resource.TestCheckResourceAttrPair("data.dirt_bucket.by_name", "created_at", "dirt_bucket.test", "created_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBucketDataSource_notFound(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_bucket" "test" {
+  name = "tf-acc-bucket-ds-missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`No bucket is named "tf-acc-bucket-ds-missing"`),
+			},
+		},
+	})
+}
+
+func TestAccBucketDataSource_exactlyOne(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_bucket" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured: \[id,name\]`),
+			},
+			{
+				Config: `
+data "dirt_bucket" "test" {
+  id   = "bucket-1"
+  name = "tf-acc-bucket-ds"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured: \[id,name\]`),
+			},
+		},
+	})
+}
@@ -113,7 +113,7 @@ func (r *BucketResource) Create(ctx context.Context, req resource.CreateRequest,
 	createReq := client.CreateBucketRequest{ Name: name }
 	bucket, err := r.client.CreateBucket(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create bucket, got error: %s", err))
+		addClientError(&resp.Diagnostics, "create", "bucket", name, err)
 		return
 	}
 
@@ -141,7 +141,7 @@ func (r *BucketResource) Read(ctx context.Context, req resource.ReadRequest, res
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read bucket, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "bucket", data.ID.ValueString(), err)
 		return
 	}
 
@@ -168,7 +168,7 @@ func (r *BucketResource) Update(ctx context.Context, req resource.UpdateRequest,
 	updateReq := client.UpdateBucketRequest{ Name: name }
 	bucket, err := r.client.UpdateBucket(ctx, data.ID.ValueString(), updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update bucket, got error: %s", err))
+		addClientError(&resp.Diagnostics, "update", "bucket", data.ID.ValueString(), err)
 		return
 	}
 
@@ -189,7 +189,7 @@ func (r *BucketResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if !data.ForceDestroy.IsNull() && !data.ForceDestroy.IsUnknown() && !data.ForceDestroy.ValueBool() {
 		objects, err := r.client.ListObjects(ctx, data.ID.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list bucket objects before delete, got error: %s", err))
+			addClientError(&resp.Diagnostics, "list objects of", "bucket", data.ID.ValueString(), err)
 			return
 		}
 		if len(objects) > 0 {
@@ -206,7 +206,7 @@ func (r *BucketResource) Delete(ctx context.Context, req resource.DeleteRequest,
 			// Already gone; consider delete successful (idempotent)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete bucket, got error: %s", err))
+		addClientError(&resp.Diagnostics, "delete", "bucket", data.ID.ValueString(), err)
 		return
 	}
 }
@@ -216,11 +216,13 @@ func (r *BucketResource) ImportState(ctx context.Context, req resource.ImportSta
 
 	bucket, err := r.client.GetBucket(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to import bucket, got error: %s", err))
+		addClientError(&resp.Diagnostics, "import", "bucket", req.ID, err)
 		return
 	}
 
 	data.Name = types.StringValue(bucket.Name)
+	// force_destroy only exists in Terraform; imported buckets get the schema default.
+	data.ForceDestroy = types.BoolValue(true)
 	data.CreatedAt = types.StringValue(bucket.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
 	data.UpdatedAt = types.StringValue(bucket.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
 
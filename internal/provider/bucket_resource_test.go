@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func TestAccBucketResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccBucketResourceConfig("tf-acc-bucket", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_bucket.test", "name", "tf-acc-bucket"),
+					resource.TestCheckResourceAttr("dirt_bucket.test", "force_destroy", "true"),
+					resource.TestCheckResourceAttrSet("dirt_bucket.test", "id"),
+					resource.TestCheckResourceAttrSet("dirt_bucket.test", "created_at"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "dirt_bucket.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccBucketResourceConfig("tf-acc-bucket-renamed", true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_bucket.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_bucket.test", "name", "tf-acc-bucket-renamed"),
+					testAccCaptureID("dirt_bucket.test", &id),
+				),
+			},
+			// Out-of-band deletion is detected and the bucket recreated
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteBucket(context.Background(), id); err != nil {
+						t.Fatalf("deleting bucket out of band: %s", err)
+					}
+				},
+				Config: testAccBucketResourceConfig("tf-acc-bucket-renamed", true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_bucket.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_bucket.test", &id),
+			},
+		},
+	})
+}
+
+func TestAccBucketResource_forceDestroyFalse(t *testing.T) {
+	env := newTestAccEnv(t)
+	var bucketID, objectID string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBucketResourceConfig("tf-acc-bucket-keep", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_bucket.test", "force_destroy", "false"),
+					testAccCaptureID("dirt_bucket.test", &bucketID),
+				),
+			},
+			// A bucket holding objects unknown to Terraform refuses to be destroyed
+			{
+				PreConfig: func() {
+					obj, err := env.Client.CreateObject(context.Background(), bucketID, client.CreateObjectRequest{
+						BucketID: bucketID,
+						Path:     "out-of-band.txt",
+						Content:  "aGVsbG8=",
+					})
+					if err != nil {
+						t.Fatalf("creating object out of band: %s", err)
+					}
+					objectID = obj.ID
+				},
+				Config:      testAccBucketResourceConfig("tf-acc-bucket-keep", false),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`Bucket not empty`),
+			},
+			// Once emptied, the bucket can be destroyed again
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteObject(context.Background(), bucketID, objectID); err != nil {
+						t.Fatalf("deleting object out of band: %s", err)
+					}
+				},
+				Config: testAccBucketResourceConfig("tf-acc-bucket-keep", false),
+			},
+		},
+	})
+}
+
+func testAccBucketResourceConfig(name string, forceDestroy bool) string {
+	return fmt.Sprintf(`
+resource "dirt_bucket" "test" {
+  name          = %[1]q
+  force_destroy = %[2]t
+}
+`, name, forceDestroy)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &BucketsDataSource{}
+
+func NewBucketsDataSource() datasource.DataSource {
+	return &BucketsDataSource{}
+}
+
+// BucketsDataSource defines the data source implementation.
+type BucketsDataSource struct {
+	client *client.Client
+}
+
+// BucketsDataSourceModel describes the data source data model.
+type BucketsDataSourceModel struct {
+	NamePrefix types.String       `tfsdk:"name_prefix"`
+	NameRegex  types.String       `tfsdk:"name_regex"`
+	IDs        []types.String     `tfsdk:"ids"`
+	Buckets    []BucketsItemModel `tfsdk:"buckets"`
+}
+
+// BucketsItemModel describes a single bucket in the list.
+type BucketsItemModel struct {
+	ID          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	ObjectCount types.Int64  `tfsdk:"object_count"`
+	CreatedAt   types.String `tfsdk:"created_at"`
+	UpdatedAt   types.String `tfsdk:"updated_at"`
+}
+
+func (d *BucketsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_buckets"
+}
+
+func (d *BucketsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud buckets data source. Lists the buckets whose name matches `name_prefix` and `name_regex`, or all buckets when neither is set.",
+
+		Attributes: map[string]schema.Attribute{
+			"name_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only return buckets whose name starts with this prefix",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return buckets whose name matches this regular expression ([RE2 syntax](https://github.com/google/re2/wiki/Syntax))",
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "IDs of the matching buckets, in the same order as `buckets`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"buckets": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching buckets sorted by name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Bucket identifier",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Bucket name",
+							Computed:            true,
+						},
+						"object_count": schema.Int64Attribute{
+							MarkdownDescription: "Number of objects stored in the bucket",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Bucket creation timestamp",
+							Computed:            true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Bucket last updated timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *BucketsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *BucketsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data BucketsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var nameRegex *regexp.Regexp
+	if !data.NameRegex.IsNull() {
+		re, err := regexp.Compile(data.NameRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "Invalid Regular Expression", fmt.Sprintf("name_regex must be a valid regular expression, got error: %s", err))
+			return
+		}
+		nameRegex = re
+	}
+
+	// Get the buckets from the API
+	buckets, err := d.client.ListBuckets(ctx, "")
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "buckets", "", err)
+		return
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+
+	data.IDs = []types.String{}
+	data.Buckets = []BucketsItemModel{}
+	for _, bucket := range buckets {
+		if !strings.HasPrefix(bucket.Name, data.NamePrefix.ValueString()) {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(bucket.Name) {
+			continue
+		}
+
+		objects, err := d.client.ListObjects(ctx, bucket.ID)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "list", "objects", bucket.ID, err)
+			return
+		}
+
+		data.IDs = append(data.IDs, types.StringValue(bucket.ID))
+		data.Buckets = append(data.Buckets, BucketsItemModel{
+			ID:          types.StringValue(bucket.ID),
+			Name:        types.StringValue(bucket.Name),
+			ObjectCount: types.Int64Value(int64(len(objects))),
+			CreatedAt:   types.StringValue(bucket.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+			UpdatedAt:   types.StringValue(bucket.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
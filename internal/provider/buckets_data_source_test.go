@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+const testAccBucketsDataSourceResources = `
+resource "dirt_bucket" "assets" {
+  name = "tf-acc-buckets-assets"
+}
+
+resource "dirt_bucket" "logs" {
+  name = "tf-acc-buckets-logs"
+}
+
+resource "dirt_bucket" "other" {
+  name = "tf-acc-other-buckets"
+}
+
+resource "dirt_object" "index" {
+  bucket_id      = dirt_bucket.assets.id
+  path           = "index.html"
+  content_base64 = base64encode("hello")
+}
+
+resource "dirt_object" "style" {
+  bucket_id      = dirt_bucket.assets.id
+  path           = "style.css"
+  content_base64 = base64encode("body {}")
+}
+`
+
+func TestAccBucketsDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBucketsDataSourceResources + `
+data "dirt_buckets" "prefix" {
+  name_prefix = "tf-acc-buckets-"
+
+  depends_on = [dirt_bucket.assets, dirt_bucket.logs, dirt_bucket.other, dirt_object.index, dirt_object.style]
+}
+
+data "dirt_buckets" "regex" {
+  name_regex = "^tf-acc-.*-(logs|buckets)$"
+
+  depends_on = [dirt_bucket.assets, dirt_bucket.logs, dirt_bucket.other]
+}
+
+data "dirt_buckets" "both" {
+  name_prefix = "tf-acc-buckets-"
+  name_regex  = "logs"
+
+  depends_on = [dirt_bucket.assets, dirt_bucket.logs, dirt_bucket.other]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "buckets.#", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_buckets.prefix", "buckets.0.id", "dirt_bucket.assets", "id"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "buckets.0.name", "tf-acc-buckets-assets"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "buckets.0.object_count", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_buckets.prefix", "buckets.0.created_at", "dirt_bucket.assets", "created_at"),
+					resource.TestCheckResourceAttrSet("data.dirt_buckets.prefix", "buckets.0.updated_at"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "buckets.1.name", "tf-acc-buckets-logs"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "buckets.1.object_count", "0"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.prefix", "ids.#", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_buckets.prefix", "ids.0", "dirt_bucket.assets", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_buckets.prefix", "ids.1", "dirt_bucket.logs", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_buckets.regex", "buckets.#", "2"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.regex", "buckets.0.name", "tf-acc-buckets-logs"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.regex", "buckets.1.name", "tf-acc-other-buckets"),
+
+					resource.TestCheckResourceAttr("data.dirt_buckets.both", "buckets.#", "1"),
+					resource.TestCheckResourceAttr("data.dirt_buckets.both", "buckets.0.name", "tf-acc-buckets-logs"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBucketsDataSource_invalidRegex(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_buckets" "test" {
+  name_regex = "tf-acc-("
+}
+`,
+				ExpectError: regexp.MustCompile(`name_regex must be a valid regular expression`),
+			},
+		},
+	})
+}
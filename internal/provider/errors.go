@@ -4,19 +4,107 @@
 package provider
 
 import (
-    "strings"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/terraform-provider-dirt/internal/client"
 )
 
 // isNotFound returns true when the underlying client reported a missing resource.
 // We intentionally avoid changing the client API; it currently returns errors with
 // messages like "<resource> not found" on HTTP 404.
 func isNotFound(err error) bool {
-    if err == nil {
-        return false
-    }
-    // Unwrap in case of wrapped errors
-    // Note: using errors.Is would need a sentinel in client; we keep it simple here.
-    msg := err.Error()
-    return strings.Contains(strings.ToLower(msg), "not found")
+	if err == nil {
+		return false
+	}
+	// Unwrap in case of wrapped errors
+	// Note: using errors.Is would need a sentinel in client; we keep it simple here.
+	msg := err.Error()
+	return strings.Contains(strings.ToLower(msg), "not found")
+}
+
+// isExpired returns true when the client reported a metadata entry that has expired,
+// which the API distinguishes from one that does not exist.
+func isExpired(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && apiErr.Code == "expired"
+}
+
+// addClientError reports a failed API call to <action> a <resourceType>. id names the
+// affected resource when known. Authentication and permission failures get dedicated
+// diagnostics telling the practitioner how to fix them; any other error is reported as
+// a generic client error.
+func addClientError(diags *diag.Diagnostics, action, resourceType, id string, err error) {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s %s, got error: %s", action, resourceType, err))
+		return
+	}
+
+	resource := resourceType
+	if id != "" {
+		resource = fmt.Sprintf("%s %q", resourceType, id)
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		diags.AddError("Authentication Failed",
+			fmt.Sprintf("Unable to %s %s: the DirtCloud API rejected the credentials (%s).\n\n"+
+				"Check your token: set the provider's token argument or the DIRT_TOKEN environment variable to a valid DirtCloud API token.",
+				action, resource, apiErr.Message))
+	case http.StatusForbidden:
+		diags.AddError("Permission Denied",
+			fmt.Sprintf("The configured token is not allowed to %s %s: %s\n\n"+
+				"Use a token whose role and project scope permit this action, or ask a DirtCloud administrator to grant it.",
+				action, resource, apiErr.Message))
+	default:
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s %s, got error: %s", action, resourceType, err))
+	}
 }
 
+// addLookupError reports a lookup of a <resourceType> by name that did not match exactly
+// one resource. scope describes any further restriction of the lookup, e.g. ` in project
+// "proj-1"`; candidates describe the matching resources, if any, so practitioners can
+// pick one by ID.
+func addLookupError(diags *diag.Diagnostics, resourceType, name, scope string, candidates []string) {
+	title := strings.ToUpper(resourceType[:1]) + resourceType[1:]
+
+	if len(candidates) == 0 {
+		diags.AddAttributeError(path.Root("name"), title+" Not Found",
+			fmt.Sprintf("No %s is named %q%s.", resourceType, name, scope))
+		return
+	}
+
+	diags.AddAttributeError(path.Root("name"), "Multiple "+title+"s Found",
+		fmt.Sprintf("%d %ss are named %q%s:\n\n  - %s\n\nLook the %s up by id instead.",
+			len(candidates), resourceType, name, scope, strings.Join(candidates, "\n  - "), resourceType))
+}
+
+// lookupByName returns the single item named name among the result of a list request
+// filtered by name, or reports a lookup error and returns nil. Servers may match the
+// filter by prefix or substring, so only exact matches count. nameOf returns the name of
+// an item, and candidate describes a matching item in the error.
+func lookupByName[T any](diags *diag.Diagnostics, resourceType, name, scope string, items []T, nameOf, candidate func(T) string) *T {
+	var matches []T
+	for _, item := range items {
+		if nameOf(item) == name {
+			matches = append(matches, item)
+		}
+	}
+
+	if len(matches) != 1 {
+		candidates := make([]string, 0, len(matches))
+		for _, item := range matches {
+			candidates = append(candidates, candidate(item))
+		}
+		addLookupError(diags, resourceType, name, scope, candidates)
+		return nil
+	}
+
+	return &matches[0]
+}
@@ -0,0 +1,246 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &EventsDataSource{}
+
+func NewEventsDataSource() datasource.DataSource {
+	return &EventsDataSource{}
+}
+
+// EventsDataSource defines the data source implementation.
+type EventsDataSource struct {
+	client *client.Client
+}
+
+// EventsDataSourceModel describes the data source data model.
+type EventsDataSourceModel struct {
+	ResourceID   types.String `tfsdk:"resource_id"`
+	ResourceType types.String `tfsdk:"resource_type"`
+	Action       types.String `tfsdk:"action"`
+	Since        types.String `tfsdk:"since"`
+	Until        types.String `tfsdk:"until"`
+	Events       []EventModel `tfsdk:"events"`
+}
+
+// EventModel describes a single audit event.
+type EventModel struct {
+	ID           types.String `tfsdk:"id"`
+	Actor        types.String `tfsdk:"actor"`
+	Source       types.String `tfsdk:"source"`
+	Action       types.String `tfsdk:"action"`
+	ResourceType types.String `tfsdk:"resource_type"`
+	ResourceID   types.String `tfsdk:"resource_id"`
+	Before       types.Map    `tfsdk:"before"`
+	After        types.Map    `tfsdk:"after"`
+	Timestamp    types.String `tfsdk:"timestamp"`
+}
+
+func (d *EventsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_events"
+}
+
+func (d *EventsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud audit events data source. Lists who changed which resource, when, and through which client (console, another pipeline or Terraform).",
+
+		Attributes: map[string]schema.Attribute{
+			"resource_id": schema.StringAttribute{
+				MarkdownDescription: "Only return events for this resource ID",
+				Optional:            true,
+			},
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "Only return events for this resource type (project, instance, metadata, bucket, object)",
+				Optional:            true,
+			},
+			"action": schema.StringAttribute{
+				MarkdownDescription: "Only return events with this action (create, update, delete)",
+				Optional:            true,
+			},
+			"since": schema.StringAttribute{
+				MarkdownDescription: "Only return events at or after this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"until": schema.StringAttribute{
+				MarkdownDescription: "Only return events at or before this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"events": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching events in chronological order",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Event identifier",
+							Computed:            true,
+						},
+						"actor": schema.StringAttribute{
+							MarkdownDescription: "Identity that made the change",
+							Computed:            true,
+						},
+						"source": schema.StringAttribute{
+							MarkdownDescription: "Client the change was made through, as reported by its user agent",
+							Computed:            true,
+						},
+						"action": schema.StringAttribute{
+							MarkdownDescription: "Change action (create, update, delete)",
+							Computed:            true,
+						},
+						"resource_type": schema.StringAttribute{
+							MarkdownDescription: "Type of the changed resource",
+							Computed:            true,
+						},
+						"resource_id": schema.StringAttribute{
+							MarkdownDescription: "ID of the changed resource",
+							Computed:            true,
+						},
+						"before": schema.MapAttribute{
+							MarkdownDescription: "Resource fields before the change; non-string values are JSON-encoded",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"after": schema.MapAttribute{
+							MarkdownDescription: "Resource fields after the change; non-string values are JSON-encoded",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"timestamp": schema.StringAttribute{
+							MarkdownDescription: "Event timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *EventsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *EventsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EventsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := client.EventFilter{
+		ResourceID:   data.ResourceID.ValueString(),
+		ResourceType: data.ResourceType.ValueString(),
+		Action:       data.Action.ValueString(),
+	}
+
+	if !data.Since.IsNull() {
+		since, err := time.Parse(time.RFC3339, data.Since.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("since"), "Invalid Timestamp", fmt.Sprintf("since must be an RFC 3339 timestamp, got error: %s", err))
+			return
+		}
+		filter.Since = since
+	}
+
+	if !data.Until.IsNull() {
+		until, err := time.Parse(time.RFC3339, data.Until.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("until"), "Invalid Timestamp", fmt.Sprintf("until must be an RFC 3339 timestamp, got error: %s", err))
+			return
+		}
+		filter.Until = until
+	}
+
+	// Get the events from the API
+	events, err := d.client.ListEvents(ctx, filter)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "events", "", err)
+		return
+	}
+
+	data.Events = make([]EventModel, 0, len(events))
+	for _, event := range events {
+		before, diags := eventFieldsValue(ctx, event.Before)
+		resp.Diagnostics.Append(diags...)
+		after, diags := eventFieldsValue(ctx, event.After)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		data.Events = append(data.Events, EventModel{
+			ID:           types.StringValue(event.ID),
+			Actor:        types.StringValue(event.Actor),
+			Source:       types.StringValue(event.Source),
+			Action:       types.StringValue(event.Action),
+			ResourceType: types.StringValue(event.ResourceType),
+			ResourceID:   types.StringValue(event.ResourceID),
+			Before:       before,
+			After:        after,
+			Timestamp:    types.StringValue(event.Timestamp.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// eventFieldsValue flattens event before/after fields into a string map, JSON-encoding
+// non-string values. An absent field set becomes a null map.
+func eventFieldsValue(ctx context.Context, fields map[string]interface{}) (types.Map, diag.Diagnostics) {
+	if fields == nil {
+		return types.MapNull(types.StringType), nil
+	}
+
+	values := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			values[key] = fmt.Sprint(value)
+			continue
+		}
+		values[key] = string(encoded)
+	}
+
+	return types.MapValueFrom(ctx, types.StringType, values)
+}
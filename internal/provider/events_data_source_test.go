@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccEventsDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-events"
+}
+`,
+			},
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-events-renamed"
+}
+
+data "dirt_events" "test" {
+  resource_id = dirt_project.test.id
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.#", "2"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.0.action", "create"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.0.resource_type", "project"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.0.after.name", "tf-acc-events"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.1.action", "update"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.1.before.name", "tf-acc-events"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.1.after.name", "tf-acc-events-renamed"),
+					resource.TestCheckResourceAttr("data.dirt_events.test", "events.1.source", "terraform-provider-dirt/test"),
+				),
+			},
+		},
+	})
+}
@@ -7,14 +7,17 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-provider-dirt/internal/client"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ datasource.DataSource = &InstanceDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &InstanceDataSource{}
 
 func NewInstanceDataSource() datasource.DataSource {
 	return &InstanceDataSource{}
@@ -44,19 +47,22 @@ func (d *InstanceDataSource) Metadata(ctx context.Context, req datasource.Metada
 
 func (d *InstanceDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "DirtCloud instance data source",
+		MarkdownDescription: "DirtCloud instance data source. Looks up an existing instance by `id` or by `name`; exactly one of them must be set. Instance names are only unique within a project, so set `project_id` to narrow a lookup by name.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				MarkdownDescription: "Instance identifier",
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
 			},
 			"project_id": schema.StringAttribute{
-				MarkdownDescription: "ID of the project this instance belongs to",
+				MarkdownDescription: "ID of the project this instance belongs to. May only be set together with `name`, to look the instance up within that project",
+				Optional:            true,
 				Computed:            true,
 			},
 			"name": schema.StringAttribute{
 				MarkdownDescription: "Instance name",
+				Optional:            true,
 				Computed:            true,
 			},
 			"cpu": schema.Int64Attribute{
@@ -87,6 +93,19 @@ func (d *InstanceDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 	}
 }
 
+func (d *InstanceDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("name"),
+		),
+		datasourcevalidator.Conflicting(
+			path.MatchRoot("id"),
+			path.MatchRoot("project_id"),
+		),
+	}
+}
+
 func (d *InstanceDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -117,11 +136,37 @@ func (d *InstanceDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	// Get the instance from the API
-	instance, err := d.client.GetInstance(ctx, data.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read instance, got error: %s", err))
-		return
+	var instance *client.Instance
+
+	if !data.ID.IsNull() {
+		// Get the instance from the API
+		i, err := d.client.GetInstance(ctx, data.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "read", "instance", data.ID.ValueString(), err)
+			return
+		}
+		instance = i
+	} else {
+		name := data.Name.ValueString()
+		projectID := data.ProjectID.ValueString()
+
+		// Look the instance up by name, within the project if one is given
+		instances, err := d.client.ListInstances(ctx, projectID, name, "")
+		if err != nil {
+			addClientError(&resp.Diagnostics, "list", "instances", "", err)
+			return
+		}
+
+		scope := ""
+		if projectID != "" {
+			scope = fmt.Sprintf(" in project %q", projectID)
+		}
+		instance = lookupByName(&resp.Diagnostics, "instance", name, scope, instances,
+			func(i client.Instance) string { return i.Name },
+			func(i client.Instance) string { return fmt.Sprintf("%s (project %s)", i.ID, i.ProjectID) })
+		if instance == nil {
+			return
+		}
 	}
 
 	// Update the model with the API response data
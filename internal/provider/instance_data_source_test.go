@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInstanceDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-instance-ds"
+}
+
+resource "dirt_instance" "test" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instance-ds"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+  status     = "stopped"
+}
+
+data "dirt_instance" "test" {
+  id = dirt_instance.test.id
+}
+
+data "dirt_instance" "by_name" {
+  name = dirt_instance.test.name
+}
+
+data "dirt_instance" "by_project_and_name" {
+  project_id = dirt_project.test.id
+  name       = dirt_instance.test.name
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_instance.test", "id", "dirt_instance.test", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.test", "project_id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_instance.test", "name", "tf-acc-instance-ds"),
+					resource.TestCheckResourceAttr("data.dirt_instance.test", "cpu", "1"),
+					resource.TestCheckResourceAttr("data.dirt_instance.test", "memory_mb", "512"),
+					resource.TestCheckResourceAttr("data.dirt_instance.test", "image", "alpine:3.20"),
+					resource.TestCheckResourceAttr("data.dirt_instance.test", "status", "stopped"),
+					resource.TestCheckResourceAttrSet("data.dirt_instance.test", "created_at"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.by_name", "id", "dirt_instance.test", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.by_name", "project_id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_instance.by_name", "image", "alpine:3.20"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.by_project_and_name", "id", "dirt_instance.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstanceDataSource_lookupErrors(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resources := `
+resource "dirt_project" "first" {
+  name = "tf-acc-instance-ds-first"
+}
+
+resource "dirt_project" "second" {
+  name = "tf-acc-instance-ds-second"
+}
+
+resource "dirt_instance" "first" {
+  project_id = dirt_project.first.id
+  name       = "tf-acc-instance-ds-dup"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+resource "dirt_instance" "second" {
+  project_id = dirt_project.second.id
+  name       = "tf-acc-instance-ds-dup"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+`
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: resources,
+			},
+			// The name is ambiguous across projects; the candidates are listed
+			{
+				Config: resources + `
+data "dirt_instance" "test" {
+  name = "tf-acc-instance-ds-dup"
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)2 instances are named "tf-acc-instance-ds-dup":.*\(project .*\).*\(project .*\)`),
+			},
+			// Scoping the lookup to a project resolves it
+			{
+				Config: resources + `
+data "dirt_instance" "test" {
+  project_id = dirt_project.second.id
+  name       = "tf-acc-instance-ds-dup"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_instance.test", "id", "dirt_instance.second", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.test", "project_id", "dirt_project.second", "id"),
+				),
+			},
+			{
+				Config: resources + `
+data "dirt_instance" "test" {
+  project_id = dirt_project.first.id
+  name       = "tf-acc-instance-ds-missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`No instance is named "tf-acc-instance-ds-missing" in project`),
+			},
+			{
+				Config: resources + `
+data "dirt_instance" "test" {
+  id         = dirt_instance.first.id
+  project_id = dirt_project.first.id
+}
+`,
+				ExpectError: regexp.MustCompile(`These attributes cannot be configured together: \[id,project_id\]`),
+			},
+		},
+	})
+}
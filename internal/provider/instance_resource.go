@@ -5,8 +5,10 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
@@ -20,6 +22,10 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &InstanceResource{}
 var _ resource.ResourceWithImportState = &InstanceResource{}
+var _ resource.ResourceWithModifyPlan = &InstanceResource{}
+
+// quotaWarningRatio is the fraction of a quota limit above which planning emits a warning.
+const quotaWarningRatio = 0.9
 
 func NewInstanceResource() resource.Resource {
 	return &InstanceResource{}
@@ -49,7 +55,8 @@ func (r *InstanceResource) Metadata(ctx context.Context, req resource.MetadataRe
 
 func (r *InstanceResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "DirtCloud instance resource",
+		MarkdownDescription: "DirtCloud instance resource. Planned `cpu` and `memory_mb` increases are checked against the project quota and its current usage. " +
+			"Each instance is checked on its own, so several instances that only exceed the quota together pass the plan and fail when applied.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -154,7 +161,7 @@ func (r *InstanceResource) Create(ctx context.Context, req resource.CreateReques
 
 	instance, err := r.client.CreateInstance(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create instance, got error: %s", err))
+		addClientError(&resp.Diagnostics, "create", "instance", data.Name.ValueString(), err)
 		return
 	}
 
@@ -191,7 +198,7 @@ func (r *InstanceResource) Read(ctx context.Context, req resource.ReadRequest, r
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read instance, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "instance", data.ID.ValueString(), err)
 		return
 	}
 
@@ -239,7 +246,7 @@ func (r *InstanceResource) Update(ctx context.Context, req resource.UpdateReques
 
 	instance, err := r.client.UpdateInstance(ctx, data.ID.ValueString(), updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update instance, got error: %s", err))
+		addClientError(&resp.Diagnostics, "update", "instance", data.ID.ValueString(), err)
 		return
 	}
 
@@ -272,7 +279,7 @@ func (r *InstanceResource) Delete(ctx context.Context, req resource.DeleteReques
 			// Already deleted; treat as success
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete instance, got error: %s", err))
+		addClientError(&resp.Diagnostics, "delete", "instance", data.ID.ValueString(), err)
 		return
 	}
 }
@@ -286,7 +293,7 @@ func (r *InstanceResource) ImportState(ctx context.Context, req resource.ImportS
 	// Read the instance to populate other fields
 	instance, err := r.client.GetInstance(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to import instance, got error: %s", err))
+		addClientError(&resp.Diagnostics, "import", "instance", req.ID, err)
 		return
 	}
 
@@ -302,3 +309,91 @@ func (r *InstanceResource) ImportState(ctx context.Context, req resource.ImportS
 	// Save imported data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// ModifyPlan checks planned CPU and memory changes against the project quota so that
+// over-quota changes fail at plan time instead of halfway through an apply. Terraform
+// plans each instance on its own, so every change is checked against the current usage
+// only; several changes that together exceed the quota still fail when applied.
+func (r *InstanceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy, or when the provider has not been configured yet.
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan InstanceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values only known after apply (e.g. a project created in the same run) cannot be checked.
+	if plan.ProjectID.IsUnknown() || plan.CPU.IsUnknown() || plan.MemoryMB.IsUnknown() {
+		return
+	}
+
+	deltaCPU := plan.CPU.ValueInt64()
+	deltaMemoryMB := plan.MemoryMB.ValueInt64()
+
+	// Updates in the same project only consume the difference to the current allocation.
+	if !req.State.Raw.IsNull() {
+		var state InstanceResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if state.ProjectID.ValueString() == plan.ProjectID.ValueString() {
+			deltaCPU -= state.CPU.ValueInt64()
+			deltaMemoryMB -= state.MemoryMB.ValueInt64()
+		}
+	}
+
+	if deltaCPU <= 0 && deltaMemoryMB <= 0 {
+		return
+	}
+
+	quota, err := r.client.GetProjectQuota(ctx, plan.ProjectID.ValueString())
+	if err != nil {
+		if errors.Is(err, client.ErrQuotaNotFound) {
+			// Server has no quota API (or the project is not visible yet); skip the check.
+			return
+		}
+		resp.Diagnostics.AddWarning(
+			"Unable to Check Project Quota",
+			fmt.Sprintf("Skipping quota validation for project %s, got error: %s", plan.ProjectID.ValueString(), err),
+		)
+		return
+	}
+
+	checkQuota(resp, plan.ProjectID.ValueString(), "cpu", deltaCPU, int64(quota.Usage.CPU), int64(quota.Limits.CPU))
+	checkQuota(resp, plan.ProjectID.ValueString(), "memory_mb", deltaMemoryMB, int64(quota.Usage.MemoryMB), int64(quota.Limits.MemoryMB))
+}
+
+// checkQuota adds an error when usage plus delta exceeds limit, or a warning when it comes close.
+// A zero limit means the dimension is unlimited.
+func checkQuota(resp *resource.ModifyPlanResponse, projectID, attribute string, delta, usage, limit int64) {
+	if delta <= 0 || limit <= 0 {
+		return
+	}
+
+	projected := usage + delta
+	attrPath := path.Root(attribute)
+
+	if projected > limit {
+		resp.Diagnostics.AddAttributeError(
+			attrPath,
+			"Project Quota Exceeded",
+			fmt.Sprintf("This change would raise %s usage of project %s to %d, exceeding its limit of %d (current usage %d, requested +%d).",
+				attribute, projectID, projected, limit, usage, delta),
+		)
+		return
+	}
+
+	if float64(projected) > float64(limit)*quotaWarningRatio {
+		resp.Diagnostics.AddAttributeWarning(
+			attrPath,
+			"Project Quota Nearly Exhausted",
+			fmt.Sprintf("This change would raise %s usage of project %s to %d of its %d limit.", attribute, projectID, projected, limit),
+		)
+	}
+}
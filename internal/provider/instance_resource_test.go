@@ -0,0 +1,293 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+
+	"github.com/terraform-provider-dirt/internal/client"
+	"github.com/terraform-provider-dirt/internal/server"
+)
+
+func TestAccInstanceResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create with defaults
+			{
+				Config: testAccInstanceResourceConfig("first", `name = "tf-acc-instance"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dirt_instance.test", "project_id", "dirt_project.first", "id"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "name", "tf-acc-instance"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "cpu", "2"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "memory_mb", "2048"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "image", "ubuntu:20.04"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "status", "running"),
+					resource.TestCheckResourceAttrSet("dirt_instance.test", "id"),
+					resource.TestCheckResourceAttrSet("dirt_instance.test", "created_at"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "dirt_instance.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccInstanceResourceConfig("first", `
+  name      = "tf-acc-instance-resized"
+  cpu       = 4
+  memory_mb = 4096
+  status    = "stopped"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_instance.test", "name", "tf-acc-instance-resized"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "cpu", "4"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "memory_mb", "4096"),
+					resource.TestCheckResourceAttr("dirt_instance.test", "status", "stopped"),
+					testAccCaptureID("dirt_instance.test", &id),
+				),
+			},
+			// Changing the image replaces the instance
+			{
+				Config: testAccInstanceResourceConfig("first", `
+  name      = "tf-acc-instance-resized"
+  cpu       = 4
+  memory_mb = 4096
+  status    = "stopped"
+  image     = "debian:12"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_instance.test", "image", "debian:12"),
+					testAccCheckIDChanged("dirt_instance.test", &id),
+					testAccCaptureID("dirt_instance.test", &id),
+				),
+			},
+			// Moving to another project replaces the instance
+			{
+				Config: testAccInstanceResourceConfig("second", `
+  name      = "tf-acc-instance-resized"
+  cpu       = 4
+  memory_mb = 4096
+  status    = "stopped"
+  image     = "debian:12"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dirt_instance.test", "project_id", "dirt_project.second", "id"),
+					testAccCheckIDChanged("dirt_instance.test", &id),
+					testAccCaptureID("dirt_instance.test", &id),
+				),
+			},
+			// Out-of-band deletion is detected and the instance recreated
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteInstance(context.Background(), id); err != nil {
+						t.Fatalf("deleting instance out of band: %s", err)
+					}
+				},
+				Config: testAccInstanceResourceConfig("second", `
+  name      = "tf-acc-instance-resized"
+  cpu       = 4
+  memory_mb = 4096
+  status    = "stopped"
+  image     = "debian:12"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_instance.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_instance.test", &id),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_quota(t *testing.T) {
+	env := newTestAccEnv(t, withQuota(10, 10240))
+	var warnings testAccPlanWarnings
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: warnings.Factories(env),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceQuotaConfig(""),
+			},
+			// Creating an instance beyond the limit fails at plan time
+			{
+				Config:      testAccInstanceQuotaConfig(`cpu = 12`),
+				ExpectError: regexp.MustCompile(`Error running pre-apply plan(?s:.*)Project Quota Exceeded(?s:.*)cpu\s+usage\s+of\s+project\s+proj-0001\s+to\s+12,\s+exceeding\s+its\s+limit\s+of\s+10`),
+			},
+			{
+				Config: testAccInstanceQuotaConfig(`cpu = 4`),
+				Check:  warnings.ExpectNone(),
+			},
+			// Growing above 90% of the limit warns but goes ahead
+			{
+				Config: testAccInstanceQuotaConfig(`cpu = 10`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_instance.test", "cpu", "10"),
+					warnings.Expect("Project Quota Nearly Exhausted", regexp.MustCompile(`cpu usage of project proj-0001 to 10 of its 10 limit`)),
+				),
+			},
+			// Updates only count the difference to the current allocation
+			{
+				Config:      testAccInstanceQuotaConfig(`cpu = 11`),
+				ExpectError: regexp.MustCompile(`Error running pre-apply plan(?s:.*)Project Quota Exceeded(?s:.*)\(current\s+usage\s+10,\s+requested\s+\+1\)`),
+			},
+			// so shrinking never fails, even at the limit
+			{
+				Config: testAccInstanceQuotaConfig(`cpu = 8`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_instance.test", "cpu", "8"),
+					warnings.ExpectNone(),
+				),
+			},
+			// Memory is checked the same way
+			{
+				Config:      testAccInstanceQuotaConfig(`cpu = 8`, `memory_mb = 12288`),
+				ExpectError: regexp.MustCompile(`Error running pre-apply plan(?s:.*)Project Quota Exceeded(?s:.*)memory_mb\s+usage\s+of\s+project\s+proj-0001\s+to\s+12288,\s+exceeding\s+its\s+limit\s+of\s+10240`),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_quotaUnknownProject(t *testing.T) {
+	env := newTestAccEnv(t, withQuota(4, 0))
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The project is only created at apply, so the plan cannot check its quota; the
+			// check runs once the project ID is known, before the instance is created
+			{
+				Config:      testAccInstanceQuotaConfig(`cpu = 8`),
+				ExpectError: regexp.MustCompile(`Error running apply(?s:.*)Project Quota Exceeded`),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_quotaUnsupported(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			env := newTestAccEnv(t, withQuota(4, 0), withoutQuotaAPI(status))
+			var warnings testAccPlanWarnings
+
+			env.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: warnings.Factories(env),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccInstanceQuotaConfig(""),
+					},
+					// Without a quota API the check is skipped silently, and the server
+					// rejects the instance instead
+					{
+						Config:      testAccInstanceQuotaConfig(`cpu = 8`),
+						ExpectError: regexp.MustCompile(`Error running apply(?s:.*)Client Error(?s:.*)cpu\s+quota\s+exceeded:\s+8\s+requested`),
+					},
+					{
+						Config: testAccInstanceQuotaConfig(`cpu = 4`),
+						Check:  warnings.ExpectNone(),
+					},
+				},
+			})
+		})
+	}
+}
+
+// withQuota gives every project of the in-process server a quota of cpu and memoryMB;
+// zero means unlimited.
+func withQuota(cpu, memoryMB int) testAccEnvOption {
+	return withServerOptions(func(o *server.Options) {
+		o.DefaultQuota = client.ResourceAmounts{CPU: cpu, MemoryMB: memoryMB}
+	})
+}
+
+// withoutQuotaAPI makes the in-process server answer quota requests with status, like a
+// server that does not implement them.
+func withoutQuotaAPI(status int) testAccEnvOption {
+	return withMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/quota") {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// testAccInstanceQuotaConfig declares a project, and an instance in it with the given
+// arguments unless there are none.
+func testAccInstanceQuotaConfig(arguments ...string) string {
+	config := `
+resource "dirt_project" "test" {
+  name = "tf-acc-instance-quota"
+}
+`
+	if len(arguments) == 0 || arguments[0] == "" {
+		return config
+	}
+
+	return config + fmt.Sprintf(`
+resource "dirt_instance" "test" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instance-quota"
+  image      = "alpine:3.20"
+  %s
+}
+`, strings.Join(arguments, "\n  "))
+}
+
+// testAccInstanceResourceConfig declares two projects and an instance in the project
+// named by project, with the given instance arguments.
+func testAccInstanceResourceConfig(project, arguments string) string {
+	return fmt.Sprintf(`
+resource "dirt_project" "first" {
+  name = "tf-acc-instance-first"
+}
+
+resource "dirt_project" "second" {
+  name = "tf-acc-instance-second"
+}
+
+resource "dirt_instance" "test" {
+  project_id = dirt_project.%[1]s.id
+  %[2]s
+}
+`, project, arguments)
+}
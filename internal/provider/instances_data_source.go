@@ -0,0 +1,232 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &InstancesDataSource{}
+
+func NewInstancesDataSource() datasource.DataSource {
+	return &InstancesDataSource{}
+}
+
+// InstancesDataSource defines the data source implementation.
+type InstancesDataSource struct {
+	client *client.Client
+}
+
+// InstancesDataSourceModel describes the data source data model.
+type InstancesDataSourceModel struct {
+	ProjectID    types.String              `tfsdk:"project_id"`
+	Name         types.String              `tfsdk:"name"`
+	NameRegex    types.String              `tfsdk:"name_regex"`
+	Status       types.String              `tfsdk:"status"`
+	Image        types.String              `tfsdk:"image"`
+	CPUMin       types.Int64               `tfsdk:"cpu_min"`
+	CPUMax       types.Int64               `tfsdk:"cpu_max"`
+	MemoryMBMin  types.Int64               `tfsdk:"memory_mb_min"`
+	MemoryMBMax  types.Int64               `tfsdk:"memory_mb_max"`
+	CreatedSince types.String              `tfsdk:"created_since"`
+	CreatedUntil types.String              `tfsdk:"created_until"`
+	IDs          []types.String            `tfsdk:"ids"`
+	Instances    []InstanceDataSourceModel `tfsdk:"instances"`
+}
+
+func (d *InstancesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_instances"
+}
+
+func (d *InstancesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud instances data source. Lists the instances matching all of the given filters, or all instances when none is set. Ranges are inclusive.",
+
+		Attributes: map[string]schema.Attribute{
+			"project_id": schema.StringAttribute{
+				MarkdownDescription: "Only return instances of this project",
+				Optional:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Only return instances with exactly this name",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return instances whose name matches this regular expression ([RE2 syntax](https://github.com/google/re2/wiki/Syntax))",
+				Optional:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Only return instances with this status (running, stopped)",
+				Optional:            true,
+			},
+			"image": schema.StringAttribute{
+				MarkdownDescription: "Only return instances running this image",
+				Optional:            true,
+			},
+			"cpu_min": schema.Int64Attribute{
+				MarkdownDescription: "Only return instances with at least this many CPU cores",
+				Optional:            true,
+			},
+			"cpu_max": schema.Int64Attribute{
+				MarkdownDescription: "Only return instances with at most this many CPU cores",
+				Optional:            true,
+			},
+			"memory_mb_min": schema.Int64Attribute{
+				MarkdownDescription: "Only return instances with at least this much memory in MB",
+				Optional:            true,
+			},
+			"memory_mb_max": schema.Int64Attribute{
+				MarkdownDescription: "Only return instances with at most this much memory in MB",
+				Optional:            true,
+			},
+			"created_since": schema.StringAttribute{
+				MarkdownDescription: "Only return instances created at or after this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"created_until": schema.StringAttribute{
+				MarkdownDescription: "Only return instances created at or before this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "IDs of the matching instances, in the same order as `instances`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"instances": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching instances sorted by name, then ID",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Instance identifier",
+							Computed:            true,
+						},
+						"project_id": schema.StringAttribute{
+							MarkdownDescription: "ID of the project this instance belongs to",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Instance name",
+							Computed:            true,
+						},
+						"cpu": schema.Int64Attribute{
+							MarkdownDescription: "Number of CPU cores",
+							Computed:            true,
+						},
+						"memory_mb": schema.Int64Attribute{
+							MarkdownDescription: "Memory in MB",
+							Computed:            true,
+						},
+						"image": schema.StringAttribute{
+							MarkdownDescription: "Instance image",
+							Computed:            true,
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "Instance status (running, stopped)",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Instance creation timestamp",
+							Computed:            true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Instance last updated timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *InstancesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *InstancesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data InstancesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := newListFilter(data.Name, data.NameRegex, data.CreatedSince, data.CreatedUntil, &resp.Diagnostics)
+	validateRange(data.CPUMin, data.CPUMax, "cpu_min", "cpu_max", &resp.Diagnostics)
+	validateRange(data.MemoryMBMin, data.MemoryMBMax, "memory_mb_min", "memory_mb_max", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the instances from the API; project, name and status narrow the list server-side
+	instances, err := d.client.ListInstances(ctx, data.ProjectID.ValueString(), data.Name.ValueString(), data.Status.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "instances", "", err)
+		return
+	}
+
+	sort.SliceStable(instances, func(i, j int) bool {
+		if instances[i].Name != instances[j].Name {
+			return instances[i].Name < instances[j].Name
+		}
+		return instances[i].ID < instances[j].ID
+	})
+
+	data.IDs = []types.String{}
+	data.Instances = []InstanceDataSourceModel{}
+	for _, instance := range instances {
+		if !filter.match(instance.Name, instance.CreatedAt) {
+			continue
+		}
+		if !data.Image.IsNull() && instance.Image != data.Image.ValueString() {
+			continue
+		}
+		if !inRange(int64(instance.CPU), data.CPUMin, data.CPUMax) || !inRange(int64(instance.MemoryMB), data.MemoryMBMin, data.MemoryMBMax) {
+			continue
+		}
+
+		data.IDs = append(data.IDs, types.StringValue(instance.ID))
+		data.Instances = append(data.Instances, InstanceDataSourceModel{
+			ID:        types.StringValue(instance.ID),
+			ProjectID: types.StringValue(instance.ProjectID),
+			Name:      types.StringValue(instance.Name),
+			CPU:       types.Int64Value(int64(instance.CPU)),
+			MemoryMB:  types.Int64Value(int64(instance.MemoryMB)),
+			Image:     types.StringValue(instance.Image),
+			Status:    types.StringValue(instance.Status),
+			CreatedAt: types.StringValue(instance.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+			UpdatedAt: types.StringValue(instance.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,193 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInstancesDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-instances-ds"
+}
+
+resource "dirt_instance" "web" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instances-ds-web"
+  cpu        = 2
+  memory_mb  = 2048
+  image      = "nginx:1.27"
+}
+
+resource "dirt_instance" "worker" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instances-ds-worker"
+  cpu        = 4
+  memory_mb  = 8192
+  image      = "alpine:3.20"
+  status     = "stopped"
+}
+
+resource "dirt_instance" "cache" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instances-ds-cache"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+locals {
+  instances = [dirt_instance.web, dirt_instance.worker, dirt_instance.cache]
+}
+
+data "dirt_instances" "project" {
+  project_id = dirt_project.test.id
+
+  depends_on = [local.instances]
+}
+
+data "dirt_instances" "stopped" {
+  project_id = dirt_project.test.id
+  status     = "stopped"
+
+  depends_on = [local.instances]
+}
+
+data "dirt_instances" "alpine" {
+  project_id = dirt_project.test.id
+  image      = "alpine:3.20"
+
+  depends_on = [local.instances]
+}
+
+data "dirt_instances" "ranges" {
+  name_regex    = "^tf-acc-instances-ds-"
+  cpu_min       = 2
+  memory_mb_max = 4096
+
+  depends_on = [local.instances]
+}
+
+data "dirt_instances" "name" {
+  name = "tf-acc-instances-ds-cache"
+
+  depends_on = [local.instances]
+}
+
+data "dirt_instances" "window" {
+  project_id    = dirt_project.test.id
+  created_until = "2000-01-01T00:00:00Z"
+
+  depends_on = [local.instances]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.#", "3"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.0.name", "tf-acc-instances-ds-cache"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "instances.0.id", "dirt_instance.cache", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "instances.0.project_id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.0.cpu", "1"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.0.memory_mb", "512"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.0.image", "alpine:3.20"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.0.status", "running"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "instances.0.created_at", "dirt_instance.cache", "created_at"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.1.name", "tf-acc-instances-ds-web"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "instances.2.name", "tf-acc-instances-ds-worker"),
+					resource.TestCheckResourceAttr("data.dirt_instances.project", "ids.#", "3"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "ids.0", "dirt_instance.cache", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "ids.1", "dirt_instance.web", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.project", "ids.2", "dirt_instance.worker", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_instances.stopped", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.stopped", "ids.0", "dirt_instance.worker", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_instances.alpine", "ids.#", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.alpine", "ids.0", "dirt_instance.cache", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.alpine", "ids.1", "dirt_instance.worker", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_instances.ranges", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.ranges", "ids.0", "dirt_instance.web", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_instances.name", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.name", "ids.0", "dirt_instance.cache", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_instances.window", "ids.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstancesDataSource_exactName(t *testing.T) {
+	env := newTestAccEnv(t, withoutNameFilter())
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-instances-ds-exact"
+}
+
+resource "dirt_instance" "test" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instances-ds"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+resource "dirt_instance" "longer" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-instances-ds-longer"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+data "dirt_instances" "test" {
+  project_id = dirt_project.test.id
+  name       = dirt_instance.test.name
+
+  depends_on = [dirt_instance.longer]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_instances.test", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_instances.test", "ids.0", "dirt_instance.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstancesDataSource_invalidRange(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_instances" "test" {
+  cpu_min = 8
+  cpu_max = 2
+}
+`,
+				ExpectError: regexp.MustCompile(`cpu_min \(8\) must not be greater than cpu_max \(2\)`),
+			},
+		},
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// listFilter holds the name and creation time filters shared by the list data sources.
+// The API also filters by name, but servers may match it by prefix or substring, so every
+// filter is applied client-side as well.
+type listFilter struct {
+	name      string
+	nameRegex *regexp.Regexp
+	since     time.Time
+	until     time.Time
+}
+
+// newListFilter parses the name, name_regex, created_since and created_until attributes,
+// reporting invalid values as attribute errors.
+func newListFilter(name, nameRegex, since, until types.String, diags *diag.Diagnostics) listFilter {
+	f := listFilter{name: name.ValueString()}
+
+	if !nameRegex.IsNull() {
+		re, err := regexp.Compile(nameRegex.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("name_regex"), "Invalid Regular Expression", fmt.Sprintf("name_regex must be a valid regular expression, got error: %s", err))
+		}
+		f.nameRegex = re
+	}
+
+	if !since.IsNull() {
+		t, err := time.Parse(time.RFC3339, since.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("created_since"), "Invalid Timestamp", fmt.Sprintf("created_since must be an RFC 3339 timestamp, got error: %s", err))
+		}
+		f.since = t
+	}
+
+	if !until.IsNull() {
+		t, err := time.Parse(time.RFC3339, until.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("created_until"), "Invalid Timestamp", fmt.Sprintf("created_until must be an RFC 3339 timestamp, got error: %s", err))
+		}
+		f.until = t
+	}
+
+	return f
+}
+
+// match reports whether a resource with the given name and creation time passes the filter.
+func (f listFilter) match(name string, createdAt time.Time) bool {
+	if f.name != "" && name != f.name {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(name) {
+		return false
+	}
+	if !f.since.IsZero() && createdAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && createdAt.After(f.until) {
+		return false
+	}
+	return true
+}
+
+// validateRange reports an attribute error when both bounds are set and lo exceeds hi.
+func validateRange(lo, hi types.Int64, minAttr, maxAttr string, diags *diag.Diagnostics) {
+	if lo.IsNull() || hi.IsNull() || lo.ValueInt64() <= hi.ValueInt64() {
+		return
+	}
+	diags.AddAttributeError(path.Root(minAttr), "Invalid Range",
+		fmt.Sprintf("%s (%d) must not be greater than %s (%d)", minAttr, lo.ValueInt64(), maxAttr, hi.ValueInt64()))
+}
+
+// inRange reports whether v lies within the optional inclusive bounds lo and hi.
+func inRange(v int64, lo, hi types.Int64) bool {
+	if !lo.IsNull() && v < lo.ValueInt64() {
+		return false
+	}
+	if !hi.IsNull() && v > hi.ValueInt64() {
+		return false
+	}
+	return true
+}
@@ -5,10 +5,17 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-provider-dirt/internal/client"
 )
@@ -27,11 +34,17 @@ type MetadataDataSource struct {
 
 // MetadataDataSourceModel describes the data source data model.
 type MetadataDataSourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	Path      types.String `tfsdk:"path"`
-	Value     types.String `tfsdk:"value"`
-	CreatedAt types.String `tfsdk:"created_at"`
-	UpdatedAt types.String `tfsdk:"updated_at"`
+	ID             types.String  `tfsdk:"id"`
+	Path           MetadataPath  `tfsdk:"path"`
+	Value          types.String  `tfsdk:"value"`
+	ValueJSON      types.Dynamic `tfsdk:"value_json"`
+	Sensitive      types.Bool    `tfsdk:"sensitive"`
+	SensitiveValue types.String  `tfsdk:"sensitive_value"`
+	ValueSHA256    types.String  `tfsdk:"value_sha256"`
+	Version        types.Int64   `tfsdk:"version"`
+	ExpiresAt      types.String  `tfsdk:"expires_at"`
+	CreatedAt      types.String  `tfsdk:"created_at"`
+	UpdatedAt      types.String  `tfsdk:"updated_at"`
 }
 
 func (d *MetadataDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -40,7 +53,7 @@ func (d *MetadataDataSource) Metadata(ctx context.Context, req datasource.Metada
 
 func (d *MetadataDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "DirtCloud metadata data source",
+		MarkdownDescription: "DirtCloud metadata data source. Reads the current value of a metadata entry, or the value as of a past `version`.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -48,11 +61,41 @@ func (d *MetadataDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				Computed:            true,
 			},
 			"path": schema.StringAttribute{
-				MarkdownDescription: "Metadata path identifier",
+				MarkdownDescription: "Metadata path identifier. Surrounding whitespace and leading, trailing and repeated slashes are ignored",
+				CustomType:          MetadataPathType{},
 				Required:            true,
+				Validators: []validator.String{
+					metadataPathValidator{},
+				},
 			},
 			"value": schema.StringAttribute{
-				MarkdownDescription: "Metadata value",
+				MarkdownDescription: "Metadata value. Null when `sensitive` is `true`",
+				Computed:            true,
+			},
+			"value_json": schema.DynamicAttribute{
+				MarkdownDescription: "Metadata value decoded from JSON, like `jsondecode(value)`; null when the value is not valid JSON or `sensitive` is `true`",
+				Computed:            true,
+			},
+			"sensitive": schema.BoolAttribute{
+				MarkdownDescription: "Whether the server flags the entry as a secret",
+				Computed:            true,
+			},
+			"sensitive_value": schema.StringAttribute{
+				MarkdownDescription: "Same as `value` when `sensitive` is `true`, null otherwise",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"value_sha256": schema.StringAttribute{
+				MarkdownDescription: "Hex-encoded SHA-256 digest of the value",
+				Computed:            true,
+			},
+			"version": schema.Int64Attribute{
+				MarkdownDescription: "Version to read. Defaults to the current version",
+				Optional:            true,
+				Computed:            true,
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "Timestamp after which the server treats the metadata as deleted, or null if it does not expire",
 				Computed:            true,
 			},
 			"created_at": schema.StringAttribute{
@@ -60,7 +103,7 @@ func (d *MetadataDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				Computed:            true,
 			},
 			"updated_at": schema.StringAttribute{
-				MarkdownDescription: "Metadata last updated timestamp",
+				MarkdownDescription: "Timestamp of the last update, or when `version` was written if it is set",
 				Computed:            true,
 			},
 		},
@@ -97,20 +140,117 @@ func (d *MetadataDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
+	if !data.Version.IsNull() && data.Version.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("version"), "Invalid Metadata Version",
+			fmt.Sprintf("Metadata versions start at 1, got %d.", data.Version.ValueInt64()))
+		return
+	}
+
 	// Get the metadata from the API
-	metadata, err := d.client.GetMetadataByPath(ctx, data.Path.ValueString())
+	metadata, err := d.client.GetMetadataByPath(ctx, data.Path.Canonical())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "metadata", data.Path.ValueString(), err)
 		return
 	}
 
 	// Update the model with the API response data
 	data.ID = types.StringValue(metadata.ID)
-	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	data.Path = NewMetadataPathValue(metadata.Path)
 	data.CreatedAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
-	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	data.ExpiresAt = types.StringNull()
+	if metadata.ExpiresAt != nil {
+		data.ExpiresAt = types.StringValue(metadata.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"))
+	}
+
+	if data.Version.IsNull() {
+		data.setValue(ctx, metadata.Value, metadata.Secret)
+		data.ValueSHA256 = types.StringValue(metadata.ValueSHA256)
+		data.Version = types.Int64Value(int64(metadata.Version))
+		data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	} else {
+		// Read the pinned version instead of the current value
+		version, err := d.client.GetMetadataVersion(ctx, metadata.ID, int(data.Version.ValueInt64()))
+		if err != nil {
+			addClientError(&resp.Diagnostics, "read", "metadata version", fmt.Sprintf("%s@%d", metadata.ID, data.Version.ValueInt64()), err)
+			return
+		}
+
+		data.setValue(ctx, version.Value, version.Secret)
+		data.ValueSHA256 = types.StringValue(version.ValueSHA256)
+		data.UpdatedAt = types.StringValue(version.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	}
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// setValue stores a metadata value in the model. Secret values only go into
+// sensitive_value, which Terraform redacts.
+func (m *MetadataDataSourceModel) setValue(ctx context.Context, value string, secret bool) {
+	m.Value = types.StringNull()
+	m.ValueJSON = types.DynamicNull()
+	m.Sensitive = types.BoolValue(secret)
+	m.SensitiveValue = types.StringNull()
+	if secret {
+		m.SensitiveValue = types.StringValue(value)
+		return
+	}
+
+	m.Value = types.StringValue(value)
+	if decoded, ok := decodeJSONValue(ctx, value); ok {
+		m.ValueJSON = types.DynamicValue(decoded)
+	}
+}
+
+// decodeJSONValue decodes a JSON document into a framework value the way Terraform's
+// jsondecode does: objects become objects, arrays tuples, and numbers keep their full
+// precision. JSON null is returned as a null string. It reports false if s is not a
+// single valid JSON value.
+func decodeJSONValue(ctx context.Context, s string) (attr.Value, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false
+	}
+
+	return jsonAttrValue(ctx, v), true
+}
+
+// jsonAttrValue converts a value decoded by encoding/json with UseNumber.
+func jsonAttrValue(ctx context.Context, v interface{}) attr.Value {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrValues := make(map[string]attr.Value, len(v))
+		for k, elem := range v {
+			attrValues[k] = jsonAttrValue(ctx, elem)
+			attrTypes[k] = attrValues[k].Type(ctx)
+		}
+		return types.ObjectValueMust(attrTypes, attrValues)
+	case []interface{}:
+		elemTypes := make([]attr.Type, len(v))
+		elemValues := make([]attr.Value, len(v))
+		for i, elem := range v {
+			elemValues[i] = jsonAttrValue(ctx, elem)
+			elemTypes[i] = elemValues[i].Type(ctx)
+		}
+		return types.TupleValueMust(elemTypes, elemValues)
+	case json.Number:
+		f, _, err := big.ParseFloat(string(v), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return types.StringValue(string(v))
+		}
+		return types.NumberValue(f)
+	case string:
+		return types.StringValue(v)
+	case bool:
+		return types.BoolValue(v)
+	default:
+		return types.StringNull()
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccMetadataDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_metadata" "test" {
+  path  = "tf-acc-ds/region"
+  value = "eu-west"
+}
+
+data "dirt_metadata" "test" {
+  path = dirt_metadata.test.path
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_metadata.test", "id", "dirt_metadata.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "path", "tf-acc-ds/region"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "value", "eu-west"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata.test", "value_json"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "sensitive", "false"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata.test", "sensitive_value"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "value_sha256", valueSHA256("eu-west")),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "version", "1"),
+					resource.TestCheckResourceAttrSet("data.dirt_metadata.test", "created_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMetadataDataSource_invalidVersion(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_metadata" "test" {
+  path    = "tf-acc-ds/region"
+  version = 0
+}
+`,
+				ExpectError: regexp.MustCompile(`Metadata versions start at 1, got 0`),
+			},
+		},
+	})
+}
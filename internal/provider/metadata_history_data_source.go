@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &MetadataHistoryDataSource{}
+
+func NewMetadataHistoryDataSource() datasource.DataSource {
+	return &MetadataHistoryDataSource{}
+}
+
+// MetadataHistoryDataSource defines the data source implementation.
+type MetadataHistoryDataSource struct {
+	client *client.Client
+}
+
+// MetadataHistoryDataSourceModel describes the data source data model.
+type MetadataHistoryDataSourceModel struct {
+	Path           MetadataPath           `tfsdk:"path"`
+	ID             types.String           `tfsdk:"id"`
+	CurrentVersion types.Int64            `tfsdk:"current_version"`
+	Versions       []MetadataVersionModel `tfsdk:"versions"`
+}
+
+// MetadataVersionModel describes a single version of a metadata entry.
+type MetadataVersionModel struct {
+	Version        types.Int64  `tfsdk:"version"`
+	Path           types.String `tfsdk:"path"`
+	Value          types.String `tfsdk:"value"`
+	Sensitive      types.Bool   `tfsdk:"sensitive"`
+	SensitiveValue types.String `tfsdk:"sensitive_value"`
+	ValueSHA256    types.String `tfsdk:"value_sha256"`
+	CreatedAt      types.String `tfsdk:"created_at"`
+}
+
+func (d *MetadataHistoryDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata_history"
+}
+
+func (d *MetadataHistoryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud metadata history data source. Lists every version of a metadata entry, e.g. to find the value a feature flag had before it was changed.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Metadata path identifier. Surrounding whitespace and leading, trailing and repeated slashes are ignored",
+				CustomType:          MetadataPathType{},
+				Required:            true,
+				Validators: []validator.String{
+					metadataPathValidator{},
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Metadata identifier",
+				Computed:            true,
+			},
+			"current_version": schema.Int64Attribute{
+				MarkdownDescription: "Current version of the metadata",
+				Computed:            true,
+			},
+			"versions": schema.ListNestedAttribute{
+				MarkdownDescription: "Versions of the metadata, oldest first",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"version": schema.Int64Attribute{
+							MarkdownDescription: "Version number",
+							Computed:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Metadata path as of this version",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Metadata value as of this version. Null when `sensitive` is `true`",
+							Computed:            true,
+						},
+						"sensitive": schema.BoolAttribute{
+							MarkdownDescription: "Whether the server flagged the entry as a secret as of this version",
+							Computed:            true,
+						},
+						"sensitive_value": schema.StringAttribute{
+							MarkdownDescription: "Same as `value` when `sensitive` is `true`, null otherwise",
+							Computed:            true,
+							Sensitive:           true,
+						},
+						"value_sha256": schema.StringAttribute{
+							MarkdownDescription: "Hex-encoded SHA-256 digest of the value",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "When the version was written",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *MetadataHistoryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *MetadataHistoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data MetadataHistoryDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the metadata and its versions from the API
+	metadata, err := d.client.GetMetadataByPath(ctx, data.Path.Canonical())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "read", "metadata", data.Path.ValueString(), err)
+		return
+	}
+
+	versions, err := d.client.ListMetadataVersions(ctx, metadata.ID)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "metadata versions", metadata.ID, err)
+		return
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+
+	data.ID = types.StringValue(metadata.ID)
+	data.CurrentVersion = types.Int64Value(int64(metadata.Version))
+	data.Versions = []MetadataVersionModel{}
+	for _, v := range versions {
+		version := MetadataVersionModel{
+			Version:        types.Int64Value(int64(v.Version)),
+			Path:           types.StringValue(v.Path),
+			Value:          types.StringValue(v.Value),
+			Sensitive:      types.BoolValue(v.Secret),
+			SensitiveValue: types.StringNull(),
+			ValueSHA256:    types.StringValue(v.ValueSHA256),
+			CreatedAt:      types.StringValue(v.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		}
+		if v.Secret {
+			version.Value = types.StringNull()
+			version.SensitiveValue = types.StringValue(v.Value)
+		}
+		data.Versions = append(data.Versions, version)
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccMetadataHistoryDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataHistoryDataSourceConfig("off"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "version", "1"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "current_version", "1"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.#", "1"),
+				),
+			},
+			{
+				Config: testAccMetadataHistoryDataSourceConfig("on"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "version", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_metadata_history.test", "id", "dirt_metadata.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "current_version", "2"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.#", "2"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.0.version", "1"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.0.value", "off"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.0.value_sha256", valueSHA256("off")),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.0.sensitive", "false"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata_history.test", "versions.0.sensitive_value"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.1.version", "2"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_history.test", "versions.1.value", "on"),
+					resource.TestCheckResourceAttrSet("data.dirt_metadata_history.test", "versions.1.created_at"),
+
+					// The data source reads the pinned version instead of the current value
+					resource.TestCheckResourceAttr("data.dirt_metadata.previous", "version", "1"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.previous", "value", "off"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.current", "version", "2"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.current", "value", "on"),
+				),
+			},
+		},
+	})
+}
+
+func testAccMetadataHistoryDataSourceConfig(value string) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path  = "tf-acc-history/new_ui_enabled"
+  value = %[1]q
+}
+
+data "dirt_metadata_history" "test" {
+  path = dirt_metadata.test.path
+
+  depends_on = [dirt_metadata.test]
+}
+
+data "dirt_metadata" "previous" {
+  path    = dirt_metadata.test.path
+  version = 1
+
+  depends_on = [dirt_metadata.test]
+}
+
+data "dirt_metadata" "current" {
+  path = dirt_metadata.test.path
+
+  depends_on = [dirt_metadata.test]
+}
+`, value)
+}
@@ -0,0 +1,369 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &MetadataLockResource{}
+var _ resource.ResourceWithValidateConfig = &MetadataLockResource{}
+
+func NewMetadataLockResource() resource.Resource {
+	return &MetadataLockResource{}
+}
+
+// MetadataLockResource defines the resource implementation.
+type MetadataLockResource struct {
+	client *client.Client
+}
+
+// MetadataLockResourceModel describes the resource data model.
+type MetadataLockResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	Path         MetadataPath `tfsdk:"path"`
+	Holder       types.String `tfsdk:"holder"`
+	TTL          types.String `tfsdk:"ttl"`
+	Wait         types.String `tfsdk:"wait"`
+	PollInterval types.String `tfsdk:"poll_interval"`
+	Version      types.Int64  `tfsdk:"version"`
+	AcquiredAt   types.String `tfsdk:"acquired_at"`
+	ExpiresAt    types.String `tfsdk:"expires_at"`
+}
+
+func (r *MetadataLockResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata_lock"
+}
+
+func (r *MetadataLockResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud metadata lock resource. Acquires a lock by creating the metadata entry at `path`, which only succeeds while no other holder has it, " +
+			"and releases it by deleting the entry on destroy. Use it to keep pipelines that share resources from running at the same time.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier of the metadata entry holding the lock",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Metadata path of the lock. Every holder of the same lock must use the same path",
+				CustomType:          MetadataPathType{},
+				Required:            true,
+				Validators: []validator.String{
+					metadataPathValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"holder": schema.StringAttribute{
+				MarkdownDescription: "Identifies the holder of the lock, e.g. a CI job ID. It is stored as the metadata value, so other holders waiting for the lock can tell who has it. Defaults to a random token",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				MarkdownDescription: "Time after which the lock expires if it has not been released, as a duration such as `1h`, so that a holder that never runs destroy does not keep it forever. By default the lock does not expire",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"wait": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for another holder to release the lock before failing, as a duration such as `10m`. Defaults to `0s`, failing right away",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("0s"),
+			},
+			"poll_interval": schema.StringAttribute{
+				MarkdownDescription: "How often to try to acquire the lock while waiting for it, with a last attempt when `wait` ends. Defaults to `5s`",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("5s"),
+			},
+			"version": schema.Int64Attribute{
+				MarkdownDescription: "Version of the metadata entry when the lock was acquired. The lock is only released while the entry is still at this version",
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"acquired_at": schema.StringAttribute{
+				MarkdownDescription: "When the lock was acquired",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "When the lock expires, if `ttl` is set",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *MetadataLockResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data MetadataLockResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.TTL.IsNull() && !data.TTL.IsUnknown() {
+		if _, err := ttlSeconds(data.TTL); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid Duration", err.Error())
+		}
+	}
+
+	if !data.Wait.IsNull() && !data.Wait.IsUnknown() {
+		if wait, err := time.ParseDuration(data.Wait.ValueString()); err != nil || wait < 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("wait"), "Invalid Duration", fmt.Sprintf("wait must be a non-negative duration such as \"10m\", got %q", data.Wait.ValueString()))
+		}
+	}
+
+	if !data.PollInterval.IsNull() && !data.PollInterval.IsUnknown() {
+		if interval, err := time.ParseDuration(data.PollInterval.ValueString()); err != nil || interval <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("poll_interval"), "Invalid Duration", fmt.Sprintf("poll_interval must be a positive duration such as \"5s\", got %q", data.PollInterval.ValueString()))
+		}
+	}
+}
+
+func (r *MetadataLockResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *MetadataLockResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data MetadataLockResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Holder.IsUnknown() || data.Holder.IsNull() {
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			resp.Diagnostics.AddError("Lock Holder Error", fmt.Sprintf("Unable to generate a random lock holder, got error: %s", err))
+			return
+		}
+		data.Holder = types.StringValue(hex.EncodeToString(token))
+	}
+
+	createReq := client.CreateMetadataRequest{
+		Path:  data.Path.Canonical(),
+		Value: data.Holder.ValueString(),
+	}
+	if !data.TTL.IsNull() {
+		ttl, err := ttlSeconds(data.TTL)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid Duration", err.Error())
+			return
+		}
+		createReq.TTL = ttl
+	}
+
+	// Both durations were checked by ValidateConfig
+	wait, _ := time.ParseDuration(data.Wait.ValueString())
+	pollInterval, _ := time.ParseDuration(data.PollInterval.ValueString())
+	deadline := time.Now().Add(wait)
+
+	// Acquire the lock, retrying while another holder has it
+	var metadata *client.Metadata
+	for {
+		var err error
+		metadata, err = r.client.CreateMetadataIfAbsent(ctx, createReq)
+		if err == nil {
+			break
+		}
+
+		var conflict *client.ConflictError
+		if !errors.As(err, &conflict) {
+			addClientError(&resp.Diagnostics, "acquire", "metadata lock", createReq.Path, err)
+			return
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			resp.Diagnostics.AddError("Lock Not Acquired", r.heldMessage(ctx, createReq.Path, wait))
+			return
+		}
+
+		// Retry once more at the deadline when it comes before the next poll
+		select {
+		case <-ctx.Done():
+			resp.Diagnostics.AddError("Lock Not Acquired", fmt.Sprintf("Gave up waiting for the lock %q: %s", createReq.Path, ctx.Err()))
+			return
+		case <-time.After(min(pollInterval, remaining)):
+		}
+	}
+
+	data.ID = types.StringValue(metadata.ID)
+	data.setMetadata(metadata)
+	data.AcquiredAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// heldMessage describes why the lock at lockPath could not be acquired, naming its
+// current holder when it can be read.
+func (r *MetadataLockResource) heldMessage(ctx context.Context, lockPath string, wait time.Duration) string {
+	holder := "another holder"
+	if current, err := r.client.GetMetadataByPath(ctx, lockPath); err == nil {
+		holder = fmt.Sprintf("%q", current.Value)
+	}
+
+	if wait == 0 {
+		return fmt.Sprintf("The lock %q is held by %s. Set wait to wait for it to be released.", lockPath, holder)
+	}
+	return fmt.Sprintf("The lock %q is held by %s, which did not release it within %s.", lockPath, holder, wait)
+}
+
+func (r *MetadataLockResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data MetadataLockResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadata, err := r.client.GetMetadata(ctx, data.ID.ValueString())
+	if err != nil {
+		if isExpired(err) {
+			resp.Diagnostics.AddWarning(
+				"Lock Expired",
+				fmt.Sprintf("The lock %q held by %q expired at %s, as set by its ttl, and may since have been acquired by another holder. "+
+					"It has been removed from the state; if it is still configured, Terraform will plan to acquire it again.",
+					data.Path.ValueString(), data.Holder.ValueString(), data.ExpiresAt.ValueString()),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		if isNotFound(err) {
+			// Released outside Terraform; remove from state so Terraform can acquire it again.
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		addClientError(&resp.Diagnostics, "read", "metadata lock", data.ID.ValueString(), err)
+		return
+	}
+
+	if metadata.Value != data.Holder.ValueString() {
+		resp.Diagnostics.AddWarning(
+			"Lock Taken Over",
+			fmt.Sprintf("The lock %q is now held by %q instead of %q. It has been removed from the state, and will not be released on destroy; "+
+				"if it is still configured, Terraform will plan to acquire it again.",
+				data.Path.ValueString(), metadata.Value, data.Holder.ValueString()),
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.setMetadata(metadata)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *MetadataLockResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data MetadataLockResourceModel
+
+	// Only wait and poll_interval can change in place, and they only matter while
+	// acquiring the lock, so there is nothing to send to the API
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *MetadataLockResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data MetadataLockResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Release the lock, unless it changed hands since it was acquired
+	err := r.client.DeleteMetadataIfVersion(ctx, data.ID.ValueString(), int(data.Version.ValueInt64()))
+	if err != nil {
+		var conflict *client.ConflictError
+		if errors.As(err, &conflict) {
+			resp.Diagnostics.AddWarning(
+				"Lock Not Released",
+				fmt.Sprintf("The lock %q was changed by another client since it was acquired, so it was left in place: %s", data.Path.ValueString(), conflict.Err.Message),
+			)
+			return
+		}
+		if isNotFound(err) {
+			// Already released; releasing is idempotent
+			return
+		}
+		addClientError(&resp.Diagnostics, "release", "metadata lock", data.ID.ValueString(), err)
+		return
+	}
+}
+
+// setMetadata stores the lock's metadata entry returned by the API in the model.
+func (m *MetadataLockResourceModel) setMetadata(metadata *client.Metadata) {
+	m.Path = NewMetadataPathValue(metadata.Path)
+	m.Version = types.Int64Value(int64(metadata.Version))
+	m.ExpiresAt = types.StringNull()
+	if metadata.ExpiresAt != nil {
+		m.ExpiresAt = types.StringValue(metadata.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"))
+	}
+}
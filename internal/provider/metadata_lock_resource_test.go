@@ -0,0 +1,221 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func TestAccMetadataLockResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckMetadataLockReleased(env, "tf-acc-lock/deploy"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/deploy", "pipeline-1", `ttl = "1h"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "holder", "pipeline-1"),
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "version", "1"),
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "wait", "0s"),
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "poll_interval", "5s"),
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "acquired_at", "2025-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("dirt_metadata_lock.test", "expires_at", "2025-01-01T01:00:00Z"),
+					testAccCheckMetadataValue(env, "tf-acc-lock/deploy", "pipeline-1"),
+					testAccCaptureID("dirt_metadata_lock.test", &id),
+				),
+			},
+			// Waiting only matters while acquiring, so it changes in place
+			{
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/deploy", "pipeline-1", `
+  ttl  = "1h"
+  wait = "10m"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata_lock.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("dirt_metadata_lock.test", "wait", "10m"),
+			},
+			// A lock released outside Terraform is acquired again
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteMetadata(context.Background(), id); err != nil {
+						t.Fatalf("releasing lock out of band: %s", err)
+					}
+				},
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/deploy", "pipeline-1", `
+  ttl  = "1h"
+  wait = "10m"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata_lock.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_metadata_lock.test", &id),
+			},
+		},
+	})
+}
+
+func TestAccMetadataLockResource_held(t *testing.T) {
+	env := newTestAccEnv(t)
+	var otherID string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Without wait, a held lock fails right away
+			{
+				PreConfig: func() {
+					other, err := env.Client.CreateMetadata(context.Background(), client.CreateMetadataRequest{Path: "tf-acc-lock/held", Value: "pipeline-2"})
+					if err != nil {
+						t.Fatalf("acquiring lock out of band: %s", err)
+					}
+					otherID = other.ID
+				},
+				Config:      testAccMetadataLockResourceConfig("tf-acc-lock/held", "pipeline-1", ""),
+				ExpectError: regexp.MustCompile(`The lock "tf-acc-lock/held" is held by "pipeline-2"`),
+			},
+			// With wait, it is acquired once the other holder releases it
+			{
+				PreConfig: func() {
+					go func() {
+						time.Sleep(time.Second)
+						if err := env.Client.DeleteMetadata(context.Background(), otherID); err != nil {
+							t.Errorf("releasing lock out of band: %s", err)
+						}
+					}()
+				},
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/held", "pipeline-1", `
+  wait          = "30s"
+  poll_interval = "200ms"
+`),
+				Check: testAccCheckMetadataValue(env, "tf-acc-lock/held", "pipeline-1"),
+			},
+			// A lock taken over by another holder is dropped from state, and left in
+			// place on destroy
+			{
+				PreConfig: func() {
+					lock, err := env.Client.GetMetadataByPath(context.Background(), "tf-acc-lock/held")
+					if err != nil {
+						t.Fatalf("reading lock: %s", err)
+					}
+					holder, version := "pipeline-3", lock.Version
+					if _, err := env.Client.CompareAndSwapMetadata(context.Background(), lock.ID, client.UpdateMetadataRequest{Value: &holder, IfVersion: &version}); err != nil {
+						t.Fatalf("taking over lock out of band: %s", err)
+					}
+				},
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/held", "pipeline-1", `
+  wait          = "30s"
+  poll_interval = "200ms"
+`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+		CheckDestroy: func(s *terraform.State) error {
+			lock, err := env.Client.GetMetadataByPath(context.Background(), "tf-acc-lock/held")
+			if err != nil {
+				return fmt.Errorf("reading lock after destroy: %w", err)
+			}
+			if lock.Value != "pipeline-3" {
+				return fmt.Errorf("lock held by %q after destroy, want it left to %q", lock.Value, "pipeline-3")
+			}
+			return nil
+		},
+	})
+}
+
+func TestAccMetadataLockResource_waitShorterThanPollInterval(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The lock is released before wait ends, so it is acquired by a last attempt at
+			// the deadline rather than giving up because the next poll comes too late
+			{
+				PreConfig: func() {
+					other, err := env.Client.CreateMetadata(context.Background(), client.CreateMetadataRequest{Path: "tf-acc-lock/short-wait", Value: "pipeline-2"})
+					if err != nil {
+						t.Fatalf("acquiring lock out of band: %s", err)
+					}
+					go func() {
+						time.Sleep(time.Second)
+						if err := env.Client.DeleteMetadata(context.Background(), other.ID); err != nil {
+							t.Errorf("releasing lock out of band: %s", err)
+						}
+					}()
+				},
+				Config: testAccMetadataLockResourceConfig("tf-acc-lock/short-wait", "pipeline-1", `
+  wait          = "3s"
+  poll_interval = "1m"
+`),
+				Check: testAccCheckMetadataValue(env, "tf-acc-lock/short-wait", "pipeline-1"),
+			},
+		},
+		CheckDestroy: testAccCheckMetadataLockReleased(env, "tf-acc-lock/short-wait"),
+	})
+}
+
+func TestAccMetadataLockResource_invalidDuration(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccMetadataLockResourceConfig("tf-acc-lock/invalid", "pipeline-1", `wait = "-1s"`),
+				ExpectError: regexp.MustCompile(`wait must be a non-negative duration such as "10m", got "-1s"`),
+			},
+			{
+				Config:      testAccMetadataLockResourceConfig("tf-acc-lock/invalid", "pipeline-1", `poll_interval = "0s"`),
+				ExpectError: regexp.MustCompile(`poll_interval must be a positive duration such as "5s", got "0s"`),
+			},
+			{
+				Config:      testAccMetadataLockResourceConfig("tf-acc-lock/invalid", "pipeline-1", `ttl = "soon"`),
+				ExpectError: regexp.MustCompile(`ttl must be a duration such as "24h"`),
+			},
+		},
+	})
+}
+
+// testAccCheckMetadataLockReleased checks that no metadata remains at the lock's path.
+func testAccCheckMetadataLockReleased(env *testAccEnv, path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		lock, err := env.Client.GetMetadataByPath(context.Background(), path)
+		if err == nil {
+			return fmt.Errorf("lock %q still held by %q after destroy", path, lock.Value)
+		}
+		if !isNotFound(err) {
+			return fmt.Errorf("reading lock %q: %w", path, err)
+		}
+		return nil
+	}
+}
+
+func testAccMetadataLockResourceConfig(path, holder, extra string) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata_lock" "test" {
+  path   = %[1]q
+  holder = %[2]q
+  %[3]s
+}
+`, path, holder, extra)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure the metadata path types fully satisfy framework interfaces.
+var _ basetypes.StringTypable = MetadataPathType{}
+var _ basetypes.StringValuableWithSemanticEquals = MetadataPath{}
+var _ validator.String = metadataPathValidator{}
+
+// MetadataPathType is the type of metadata path attributes. Its values compare equal
+// when they address the same entry, e.g. "/app/config/" and "app/config".
+type MetadataPathType struct {
+	basetypes.StringType
+}
+
+func (t MetadataPathType) String() string {
+	return "provider.MetadataPathType"
+}
+
+func (t MetadataPathType) ValueType(ctx context.Context) attr.Value {
+	return MetadataPath{}
+}
+
+func (t MetadataPathType) Equal(o attr.Type) bool {
+	other, ok := o.(MetadataPathType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t MetadataPathType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return MetadataPath{StringValue: in}, nil
+}
+
+func (t MetadataPathType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return MetadataPath{StringValue: stringValue}, nil
+}
+
+// MetadataPath is a metadata path as configured, which may differ from the canonical
+// path stored by the server in surrounding whitespace and slashes.
+type MetadataPath struct {
+	basetypes.StringValue
+}
+
+// NewMetadataPathValue returns a known MetadataPath.
+func NewMetadataPathValue(value string) MetadataPath {
+	return MetadataPath{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v MetadataPath) Type(ctx context.Context) attr.Type {
+	return MetadataPathType{}
+}
+
+func (v MetadataPath) Equal(o attr.Value) bool {
+	other, ok := o.(MetadataPath)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals keeps the configured spelling of a path when the server returns
+// it in canonical form.
+func (v MetadataPath) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(MetadataPath)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return v.Canonical() == newValue.Canonical(), diags
+}
+
+// Canonical returns the path in the form stored by the server.
+func (v MetadataPath) Canonical() string {
+	return client.NormalizeMetadataPath(v.ValueString())
+}
+
+// metadataPathValidator checks that a path is valid once normalized, so that paths the
+// server would reject fail at plan time.
+type metadataPathValidator struct{}
+
+func (v metadataPathValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be a path of at most %d segments of letters, digits, '_', '.' and '-', separated by '/', and at most %d characters long",
+		client.MaxMetadataPathDepth, client.MaxMetadataPathLength)
+}
+
+func (v metadataPathValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v metadataPathValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := client.ValidateMetadataPath(client.NormalizeMetadataPath(req.ConfigValue.ValueString())); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Metadata Path", err.Error())
+	}
+}
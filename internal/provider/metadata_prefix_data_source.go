@@ -0,0 +1,344 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &MetadataPrefixDataSource{}
+
+func NewMetadataPrefixDataSource() datasource.DataSource {
+	return &MetadataPrefixDataSource{}
+}
+
+// MetadataPrefixDataSource defines the data source implementation.
+type MetadataPrefixDataSource struct {
+	client *client.Client
+}
+
+// MetadataPrefixDataSourceModel describes the data source data model.
+type MetadataPrefixDataSourceModel struct {
+	Prefix          MetadataPath               `tfsdk:"prefix"`
+	Recursive       types.Bool                 `tfsdk:"recursive"`
+	StripPrefix     types.Bool                 `tfsdk:"strip_prefix"`
+	Sensitive       types.Bool                 `tfsdk:"sensitive"`
+	Values          types.Map                  `tfsdk:"values"`
+	SensitiveValues types.Map                  `tfsdk:"sensitive_values"`
+	Nested          types.Dynamic              `tfsdk:"nested"`
+	SensitiveNested types.Dynamic              `tfsdk:"sensitive_nested"`
+	Entries         []MetadataPrefixEntryModel `tfsdk:"entries"`
+}
+
+// MetadataPrefixEntryModel describes a single metadata entry under the prefix.
+type MetadataPrefixEntryModel struct {
+	ID        types.String `tfsdk:"id"`
+	Path      types.String `tfsdk:"path"`
+	Value     types.String `tfsdk:"value"`
+	Secret    types.Bool   `tfsdk:"secret"`
+	CreatedAt types.String `tfsdk:"created_at"`
+	UpdatedAt types.String `tfsdk:"updated_at"`
+}
+
+func (d *MetadataPrefixDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata_prefix"
+}
+
+func (d *MetadataPrefixDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud metadata prefix data source. Reads every metadata entry under a path prefix, e.g. `app/config/`, " +
+			"as a flat map of paths to values and as a nested object built from the `/`-separated path segments.",
+
+		Attributes: map[string]schema.Attribute{
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Path of the entries to read, matched on whole `/`-separated segments: `app/config` reads `app/config/db` but not `app/configuration`. " +
+					"Surrounding whitespace and leading, trailing and repeated slashes are ignored",
+				CustomType: MetadataPathType{},
+				Required:   true,
+				Validators: []validator.String{
+					metadataPathValidator{},
+				},
+			},
+			"recursive": schema.BoolAttribute{
+				MarkdownDescription: "Whether to include entries below nested `/` levels of the prefix. When `false`, only entries directly under the prefix are read. Defaults to `true`",
+				Optional:            true,
+			},
+			"strip_prefix": schema.BoolAttribute{
+				MarkdownDescription: "Whether to remove `prefix`, and a `/` following it, from the keys of `values` and `nested`. Defaults to `true`",
+				Optional:            true,
+			},
+			"sensitive": schema.BoolAttribute{
+				MarkdownDescription: "Whether the values are secret. When `true`, they are returned in `sensitive_values` and `sensitive_nested`, which Terraform redacts in its output, " +
+					"while `values`, `nested` and the `value` of each entry are null. When `false`, entries the server flags as secret are left out, with a warning. Defaults to `false`",
+				Optional: true,
+			},
+			"values": schema.MapAttribute{
+				MarkdownDescription: "Map of entry paths, relative to `prefix` unless `strip_prefix` is `false`, to their values. Null when `sensitive` is `true`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"sensitive_values": schema.MapAttribute{
+				MarkdownDescription: "Same as `values` when `sensitive` is `true`, null otherwise",
+				ElementType:         types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"nested": schema.DynamicAttribute{
+				MarkdownDescription: "The keys of `values` split at `/` into a nested object, so that `app/config/db/host` is read as `nested.db.host` with prefix `app/config/`. " +
+					"Entries whose path is also the parent of other entries are left out, with a warning. Null when `sensitive` is `true`",
+				Computed: true,
+			},
+			"sensitive_nested": schema.DynamicAttribute{
+				MarkdownDescription: "Same as `nested` when `sensitive` is `true`, null otherwise",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"entries": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching entries sorted by path, including an entry whose path is `prefix` itself",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Metadata identifier",
+							Computed:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Full metadata path",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Metadata value. Null when `sensitive` or `secret` is `true`",
+							Computed:            true,
+						},
+						"secret": schema.BoolAttribute{
+							MarkdownDescription: "Whether the server flags the entry as a secret",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Metadata creation timestamp",
+							Computed:            true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Metadata last updated timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *MetadataPrefixDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *MetadataPrefixDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data MetadataPrefixDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	prefix := data.Prefix.Canonical()
+	recursive := data.Recursive.IsNull() || data.Recursive.ValueBool()
+	stripPrefix := data.StripPrefix.IsNull() || data.StripPrefix.ValueBool()
+	sensitive := data.Sensitive.ValueBool()
+
+	// Get the metadata from the API
+	metadata, err := d.client.ListMetadata(ctx, prefix)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "metadata", prefix, err)
+		return
+	}
+
+	sort.SliceStable(metadata, func(i, j int) bool { return metadata[i].Path < metadata[j].Path })
+
+	values := map[string]string{}
+	var secrets []string
+	data.Entries = []MetadataPrefixEntryModel{}
+	for _, m := range metadata {
+		// The server matches the prefix on characters, e.g. app/config also returns
+		// app/configuration, so keep whole segments only
+		atPrefix := m.Path == prefix
+		relative, under := strings.CutPrefix(m.Path, prefix+"/")
+		if atPrefix {
+			relative = ""
+		} else if !under {
+			continue
+		}
+		if !recursive && strings.Contains(relative, "/") {
+			continue
+		}
+
+		entry := MetadataPrefixEntryModel{
+			ID:        types.StringValue(m.ID),
+			Path:      types.StringValue(m.Path),
+			Value:     types.StringValue(m.Value),
+			Secret:    types.BoolValue(m.Secret),
+			CreatedAt: types.StringValue(m.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+			UpdatedAt: types.StringValue(m.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		}
+		if sensitive || m.Secret {
+			entry.Value = types.StringNull()
+		}
+		data.Entries = append(data.Entries, entry)
+
+		// Secrets only go into the values Terraform redacts
+		if m.Secret && !sensitive {
+			secrets = append(secrets, m.Path)
+			continue
+		}
+
+		key := m.Path
+		if stripPrefix {
+			// The entry at the prefix itself has no path relative to it
+			if atPrefix {
+				resp.Diagnostics.AddAttributeWarning(path.Root("strip_prefix"), "Metadata Path Omitted From Values",
+					fmt.Sprintf("Metadata %q is the prefix itself, so it has no key once the prefix is stripped and is left out of values and nested; read it from entries, or set strip_prefix = false.", m.Path))
+				continue
+			}
+			key = relative
+		}
+		values[key] = m.Value
+	}
+
+	if len(secrets) > 0 {
+		resp.Diagnostics.AddAttributeWarning(path.Root("sensitive"), "Secret Metadata Omitted",
+			fmt.Sprintf("The server flags %d entries as secret, so they are left out of values and nested:\n  - %s\n\nSet sensitive = true to read them.",
+				len(secrets), strings.Join(secrets, "\n  - ")))
+	}
+
+	valuesMap, diags := types.MapValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+
+	nested, diags := nestedMetadataValue(ctx, values)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Values = types.MapNull(types.StringType)
+	data.SensitiveValues = types.MapNull(types.StringType)
+	data.Nested = types.DynamicNull()
+	data.SensitiveNested = types.DynamicNull()
+	if sensitive {
+		data.SensitiveValues = valuesMap
+		data.SensitiveNested = types.DynamicValue(nested)
+	} else {
+		data.Values = valuesMap
+		data.Nested = types.DynamicValue(nested)
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// metadataTree is a node of the nested object built from `/`-separated metadata paths.
+// Exactly one of value and children is set.
+type metadataTree struct {
+	value    *string
+	children map[string]*metadataTree
+}
+
+// nestedMetadataValue turns a map of `/`-separated paths to values into a nested object
+// value. A path that is also the parent of other paths cannot be both a string and an
+// object, so it is left out with a warning.
+func nestedMetadataValue(ctx context.Context, values map[string]string) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	root := &metadataTree{children: map[string]*metadataTree{}}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		node := root
+		segments := strings.Split(key, "/")
+		for i, segment := range segments[:len(segments)-1] {
+			child, ok := node.children[segment]
+			if !ok || child.children == nil {
+				if ok {
+					diags.AddAttributeWarning(path.Root("nested"), "Metadata Path Omitted From Nested Object",
+						fmt.Sprintf("Metadata %q is also the parent of %q, so it is left out of the nested object; read it from the flat map of values instead.", strings.Join(segments[:i+1], "/"), key))
+				}
+				child = &metadataTree{children: map[string]*metadataTree{}}
+				node.children[segment] = child
+			}
+			node = child
+		}
+
+		leaf := segments[len(segments)-1]
+		if existing, ok := node.children[leaf]; ok && existing.children != nil {
+			diags.AddAttributeWarning(path.Root("nested"), "Metadata Path Omitted From Nested Object",
+				fmt.Sprintf("Metadata %q is also the parent of other entries, so it is left out of the nested object; read it from the flat map of values instead.", key))
+			continue
+		}
+		value := values[key]
+		node.children[leaf] = &metadataTree{value: &value}
+	}
+
+	obj, objDiags := root.object(ctx)
+	diags.Append(objDiags...)
+	return obj, diags
+}
+
+// object converts the children of t into an object value.
+func (t *metadataTree) object(ctx context.Context) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	attrTypes := make(map[string]attr.Type, len(t.children))
+	attrValues := make(map[string]attr.Value, len(t.children))
+
+	for name, child := range t.children {
+		if child.value != nil {
+			attrTypes[name] = types.StringType
+			attrValues[name] = types.StringValue(*child.value)
+			continue
+		}
+
+		obj, objDiags := child.object(ctx)
+		diags.Append(objDiags...)
+		attrTypes[name] = obj.Type(ctx)
+		attrValues[name] = obj
+	}
+
+	obj, objDiags := types.ObjectValue(attrTypes, attrValues)
+	diags.Append(objDiags...)
+	return obj, diags
+}
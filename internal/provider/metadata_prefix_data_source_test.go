@@ -0,0 +1,230 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+const testAccMetadataPrefixDataSourceResources = `
+locals {
+  metadata = [
+    ["tf-acc-prefix-ds/config/db/host", "db.internal"],
+    ["tf-acc-prefix-ds/config/db/pool/size", "10"],
+    ["tf-acc-prefix-ds/config/db/port", "5432"],
+    ["tf-acc-prefix-ds/config/region", "eu-west"],
+    ["tf-acc-prefix-ds/other/key", "unrelated"],
+  ]
+}
+
+resource "dirt_metadata" "test" {
+  count = length(local.metadata)
+
+  path  = local.metadata[count.index][0]
+  value = local.metadata[count.index][1]
+}
+`
+
+func TestAccMetadataPrefixDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataPrefixDataSourceResources + `
+data "dirt_metadata_prefix" "test" {
+  prefix = "tf-acc-prefix-ds/config/"
+
+  depends_on = [dirt_metadata.test]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.test", tfjsonpath.New("values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"region":       knownvalue.StringExact("eu-west"),
+						"db/host":      knownvalue.StringExact("db.internal"),
+						"db/port":      knownvalue.StringExact("5432"),
+						"db/pool/size": knownvalue.StringExact("10"),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.test", tfjsonpath.New("nested"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"region": knownvalue.StringExact("eu-west"),
+						"db": knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"host": knownvalue.StringExact("db.internal"),
+							"port": knownvalue.StringExact("5432"),
+							"pool": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"size": knownvalue.StringExact("10"),
+							}),
+						}),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.test", tfjsonpath.New("sensitive_values"), knownvalue.Null()),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.test", tfjsonpath.New("sensitive_nested"), knownvalue.Null()),
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_metadata_prefix.test", "entries.#", "4"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_prefix.test", "entries.0.path", "tf-acc-prefix-ds/config/db/host"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_prefix.test", "entries.0.value", "db.internal"),
+					resource.TestCheckResourceAttrPair("data.dirt_metadata_prefix.test", "entries.0.id", "dirt_metadata.test.0", "id"),
+					resource.TestCheckResourceAttrSet("data.dirt_metadata_prefix.test", "entries.0.created_at"),
+					resource.TestCheckResourceAttr("data.dirt_metadata_prefix.test", "entries.3.path", "tf-acc-prefix-ds/config/region"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMetadataPrefixDataSource_options(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataPrefixDataSourceResources + `
+resource "dirt_metadata" "password" {
+  path      = "tf-acc-prefix-ds/config/db/password"
+  value     = "hunter2"
+  sensitive = true
+}
+
+data "dirt_metadata_prefix" "shallow" {
+  prefix    = "tf-acc-prefix-ds/config"
+  recursive = false
+
+  depends_on = [dirt_metadata.test, dirt_metadata.password]
+}
+
+data "dirt_metadata_prefix" "full_paths" {
+  prefix       = "tf-acc-prefix-ds/config/db/"
+  strip_prefix = false
+
+  depends_on = [dirt_metadata.test, dirt_metadata.password]
+}
+
+data "dirt_metadata_prefix" "secret" {
+  prefix    = "tf-acc-prefix-ds/config/db/"
+  sensitive = true
+
+  depends_on = [dirt_metadata.test, dirt_metadata.password]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.shallow", tfjsonpath.New("values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"region": knownvalue.StringExact("eu-west"),
+					})),
+
+					// Secrets are left out unless sensitive is set
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.full_paths", tfjsonpath.New("values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"tf-acc-prefix-ds/config/db/host":      knownvalue.StringExact("db.internal"),
+						"tf-acc-prefix-ds/config/db/port":      knownvalue.StringExact("5432"),
+						"tf-acc-prefix-ds/config/db/pool/size": knownvalue.StringExact("10"),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.full_paths", tfjsonpath.New("entries").AtSliceIndex(1), knownvalue.ObjectPartial(map[string]knownvalue.Check{
+						"path":   knownvalue.StringExact("tf-acc-prefix-ds/config/db/password"),
+						"secret": knownvalue.Bool(true),
+						"value":  knownvalue.Null(),
+					})),
+
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.secret", tfjsonpath.New("values"), knownvalue.Null()),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.secret", tfjsonpath.New("nested"), knownvalue.Null()),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.secret", tfjsonpath.New("sensitive_values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"host":      knownvalue.StringExact("db.internal"),
+						"password":  knownvalue.StringExact("hunter2"),
+						"port":      knownvalue.StringExact("5432"),
+						"pool/size": knownvalue.StringExact("10"),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.secret", tfjsonpath.New("sensitive_nested"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"host":     knownvalue.StringExact("db.internal"),
+						"password": knownvalue.StringExact("hunter2"),
+						"port":     knownvalue.StringExact("5432"),
+						"pool": knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"size": knownvalue.StringExact("10"),
+						}),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.secret", tfjsonpath.New("entries").AtSliceIndex(0).AtMapKey("value"), knownvalue.Null()),
+				},
+			},
+		},
+	})
+}
+
+func TestAccMetadataPrefixDataSource_segments(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_metadata" "prefix" {
+  path  = "tf-acc-prefix-ds-segments/config"
+  value = "itself"
+}
+
+resource "dirt_metadata" "child" {
+  path  = "tf-acc-prefix-ds-segments/config/db"
+  value = "child"
+}
+
+resource "dirt_metadata" "sibling" {
+  path  = "tf-acc-prefix-ds-segments/configuration/db"
+  value = "sibling"
+}
+
+data "dirt_metadata_prefix" "stripped" {
+  prefix = "/tf-acc-prefix-ds-segments//config/"
+
+  depends_on = [dirt_metadata.prefix, dirt_metadata.child, dirt_metadata.sibling]
+}
+
+data "dirt_metadata_prefix" "full_paths" {
+  prefix       = "tf-acc-prefix-ds-segments/config"
+  recursive    = false
+  strip_prefix = false
+
+  depends_on = [dirt_metadata.prefix, dirt_metadata.child, dirt_metadata.sibling]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					// The entry at the prefix has no key once it is stripped
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.stripped", tfjsonpath.New("values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"db": knownvalue.StringExact("child"),
+					})),
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.stripped", tfjsonpath.New("entries"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{"path": knownvalue.StringExact("tf-acc-prefix-ds-segments/config")}),
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{"path": knownvalue.StringExact("tf-acc-prefix-ds-segments/config/db")}),
+					})),
+
+					statecheck.ExpectKnownValue("data.dirt_metadata_prefix.full_paths", tfjsonpath.New("values"), knownvalue.MapExact(map[string]knownvalue.Check{
+						"tf-acc-prefix-ds-segments/config":    knownvalue.StringExact("itself"),
+						"tf-acc-prefix-ds-segments/config/db": knownvalue.StringExact("child"),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestAccMetadataPrefixDataSource_invalidPrefix(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_metadata_prefix" "test" {
+  prefix = "tf-acc-prefix-ds/../config"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Metadata Path`),
+			},
+		},
+	})
+}
@@ -5,12 +5,21 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-provider-dirt/internal/client"
 )
@@ -18,6 +27,9 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &MetadataResource{}
 var _ resource.ResourceWithImportState = &MetadataResource{}
+var _ resource.ResourceWithConfigValidators = &MetadataResource{}
+var _ resource.ResourceWithValidateConfig = &MetadataResource{}
+var _ resource.ResourceWithModifyPlan = &MetadataResource{}
 
 func NewMetadataResource() resource.Resource {
 	return &MetadataResource{}
@@ -30,11 +42,19 @@ type MetadataResource struct {
 
 // MetadataResourceModel describes the resource data model.
 type MetadataResourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	Path      types.String `tfsdk:"path"`
-	Value     types.String `tfsdk:"value"`
-	CreatedAt types.String `tfsdk:"created_at"`
-	UpdatedAt types.String `tfsdk:"updated_at"`
+	ID             types.String         `tfsdk:"id"`
+	Path           MetadataPath         `tfsdk:"path"`
+	Value          types.String         `tfsdk:"value"`
+	ValueJSON      jsontypes.Normalized `tfsdk:"value_json"`
+	ValueWO        types.String         `tfsdk:"value_wo"`
+	ValueWOVersion types.Int64          `tfsdk:"value_wo_version"`
+	Sensitive      types.Bool           `tfsdk:"sensitive"`
+	ValueSHA256    types.String         `tfsdk:"value_sha256"`
+	Version        types.Int64          `tfsdk:"version"`
+	TTL            types.String         `tfsdk:"ttl"`
+	ExpiresAt      types.String         `tfsdk:"expires_at"`
+	CreatedAt      types.String         `tfsdk:"created_at"`
+	UpdatedAt      types.String         `tfsdk:"updated_at"`
 }
 
 func (r *MetadataResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -43,7 +63,8 @@ func (r *MetadataResource) Metadata(ctx context.Context, req resource.MetadataRe
 
 func (r *MetadataResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "DirtCloud metadata resource",
+		MarkdownDescription: "DirtCloud metadata resource. The value is set with exactly one of `value`, `value_json` and `value_wo`. " +
+			"Secrets should be set with `value_wo`, which is never stored in the Terraform plan or state.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -54,12 +75,61 @@ func (r *MetadataResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"path": schema.StringAttribute{
-				MarkdownDescription: "Metadata path identifier (must be unique)",
-				Required:            true,
+				MarkdownDescription: "Metadata path identifier (must be unique): up to 16 segments of letters, digits, `_`, `.` and `-`, separated by `/`, and at most 256 characters long. " +
+					"Surrounding whitespace and leading, trailing and repeated slashes are removed before the path is sent to the server, so `/app/config/` and `app/config` address the same entry",
+				CustomType: MetadataPathType{},
+				Required:   true,
+				Validators: []validator.String{
+					metadataPathValidator{},
+				},
 			},
 			"value": schema.StringAttribute{
-				MarkdownDescription: "Metadata value",
-				Required:            true,
+				MarkdownDescription: "Metadata value. It is hidden in plan output, but stored in plain text in the Terraform state; use `value_wo` for secrets",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"value_json": schema.StringAttribute{
+				MarkdownDescription: "Metadata value as a JSON document, e.g. from `jsonencode()`. A stored document that differs from it only in whitespace or object key order, for example after another client rewrote it, is not reported as drift. Like `value`, it is hidden in plan output but stored in the Terraform state",
+				CustomType:          jsontypes.NormalizedType{},
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"value_wo": schema.StringAttribute{
+				MarkdownDescription: "Write-only metadata value, which is sent to the server but never stored in the Terraform plan or state; requires Terraform 1.11 or later. " +
+					"Changes are detected by comparing its SHA-256 digest to `value_sha256`, so editing the value in the configuration or on the server both cause an update",
+				Optional:  true,
+				WriteOnly: true,
+				Sensitive: true,
+			},
+			"value_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "Arbitrary version of `value_wo`. Changing it sends `value_wo` to the server again, e.g. when `value_wo` is not known until apply",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("value_wo")),
+				},
+			},
+			"sensitive": schema.BoolAttribute{
+				MarkdownDescription: "Whether the value is a secret. The server flags secret entries, and the `dirt_metadata` data source returns their value in `sensitive_value` only. Defaults to `false`",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"value_sha256": schema.StringAttribute{
+				MarkdownDescription: "Hex-encoded SHA-256 digest of the stored value, as reported by the server",
+				Computed:            true,
+			},
+			"version": schema.Int64Attribute{
+				MarkdownDescription: "Current version of the metadata, starting at 1 and incremented by every update. Past versions can be read with the `dirt_metadata_history` data source, or the `dirt_metadata` data source and its `version` argument",
+				Computed:            true,
+			},
+			"ttl": schema.StringAttribute{
+				MarkdownDescription: "Time to live of the metadata as a duration, e.g. `24h`. The server sets `expires_at` to this long after the metadata is created, and again after every update. Conflicts with `expires_at`",
+				Optional:            true,
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp after which the server treats the metadata as deleted. Once it has passed, the next refresh removes the resource from state with a warning, and Terraform plans to create it again if it is still configured. Computed from `ttl` when that is set",
+				Optional:            true,
+				Computed:            true,
 			},
 			"created_at": schema.StringAttribute{
 				Computed:            true,
@@ -76,6 +146,101 @@ func (r *MetadataResource) Schema(ctx context.Context, req resource.SchemaReques
 	}
 }
 
+func (r *MetadataResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("value"),
+			path.MatchRoot("value_json"),
+			path.MatchRoot("value_wo"),
+		),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("ttl"),
+			path.MatchRoot("expires_at"),
+		),
+	}
+}
+
+func (r *MetadataResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data MetadataResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Sensitive.ValueBool() && (!data.Value.IsNull() || !data.ValueJSON.IsNull()) {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("sensitive"),
+			"Secret Metadata Value Stored In State",
+			"The metadata is flagged as a secret, but its value is set with value or value_json, which Terraform hides in plan output but stores in plain text in the state. "+
+				"Set it with value_wo instead to keep it out of the state as well.",
+		)
+	}
+
+	if !data.TTL.IsNull() && !data.TTL.IsUnknown() {
+		if _, err := ttlSeconds(data.TTL); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid Duration", err.Error())
+		}
+	}
+
+	if !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
+		if _, err := time.Parse(time.RFC3339, data.ExpiresAt.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("expires_at"), "Invalid Timestamp", fmt.Sprintf("expires_at must be an RFC 3339 timestamp, got error: %s", err))
+		}
+	}
+}
+
+// ModifyPlan plans an update when the digest of a configured value_wo differs from the
+// digest of the stored value, which catches changes to either side without the value
+// itself ever being kept in state. It also plans expires_at to be cleared when neither
+// ttl nor expires_at is configured, rather than leaving it unknown.
+func (r *MetadataResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var ttl, expiresAt, stateExpiresAt types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ttl"), &ttl)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("expires_at"), &expiresAt)...)
+
+	if ttl.IsNull() && expiresAt.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires_at"), types.StringNull())...)
+	}
+
+	// Nothing to compare on create.
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	// Removing the expiry is an update of its own.
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("expires_at"), &stateExpiresAt)...)
+	if ttl.IsNull() && expiresAt.IsNull() && !stateExpiresAt.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version"), types.Int64Unknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("updated_at"), types.StringUnknown())...)
+	}
+
+	var valueWO, stateDigest types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("value_wo"), &valueWO)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("value_sha256"), &stateDigest)...)
+
+	if resp.Diagnostics.HasError() || valueWO.IsNull() || valueWO.IsUnknown() {
+		return
+	}
+
+	digest := valueSHA256(valueWO.ValueString())
+	if digest == stateDigest.ValueString() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("value_sha256"), types.StringValue(digest))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version"), types.Int64Unknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("updated_at"), types.StringUnknown())...)
+}
+
 func (r *MetadataResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -99,8 +264,10 @@ func (r *MetadataResource) Configure(ctx context.Context, req resource.Configure
 func (r *MetadataResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data MetadataResourceModel
 
-	// Read Terraform plan data into the model
+	// Read Terraform plan data into the model, and the write-only value from the
+	// configuration, as it is never part of the plan
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("value_wo"), &data.ValueWO)...)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -108,20 +275,33 @@ func (r *MetadataResource) Create(ctx context.Context, req resource.CreateReques
 
 	// Create the metadata
 	createReq := client.CreateMetadataRequest{
-		Path:  data.Path.ValueString(),
-		Value: data.Value.ValueString(),
+		Path:   data.Path.Canonical(),
+		Value:  data.value(),
+		Secret: data.Sensitive.ValueBool(),
+	}
+
+	if !data.TTL.IsNull() {
+		ttl, err := ttlSeconds(data.TTL)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid Duration", err.Error())
+			return
+		}
+		createReq.TTL = ttl
+	} else if expiresAt, ok := data.expiresAt(); ok {
+		createReq.ExpiresAt = &expiresAt
 	}
 
 	metadata, err := r.client.CreateMetadata(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "create", "metadata", data.Path.ValueString(), err)
 		return
 	}
 
 	// Update the model with the response data
 	data.ID = types.StringValue(metadata.ID)
-	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	data.Path = NewMetadataPathValue(metadata.Path)
+	data.setValue(metadata)
+	data.setExpiresAt(metadata.ExpiresAt)
 	data.CreatedAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
 	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
 
@@ -142,18 +322,31 @@ func (r *MetadataResource) Read(ctx context.Context, req resource.ReadRequest, r
 	// Get the metadata from the API
 	metadata, err := r.client.GetMetadata(ctx, data.ID.ValueString())
 	if err != nil {
+		if isExpired(err) {
+			// Expired entries are gone for good; say so, as a recreate would otherwise
+			// look like the entry was deleted out of band.
+			resp.Diagnostics.AddWarning(
+				"Metadata Expired",
+				fmt.Sprintf("The metadata %q at path %q expired at %s, as set by its ttl or expires_at, and the server no longer returns it. "+
+					"It has been removed from the state; if it is still configured, Terraform will plan to create it again.",
+					data.ID.ValueString(), data.Path.ValueString(), data.ExpiresAt.ValueString()),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		if isNotFound(err) {
 			// Resource missing remotely; remove from state so Terraform can recreate.
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "metadata", data.ID.ValueString(), err)
 		return
 	}
 
 	// Update the model with the latest data
-	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	data.Path = NewMetadataPathValue(metadata.Path)
+	data.setValue(metadata)
+	data.setExpiresAt(metadata.ExpiresAt)
 	data.CreatedAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
 	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
 
@@ -164,8 +357,10 @@ func (r *MetadataResource) Read(ctx context.Context, req resource.ReadRequest, r
 func (r *MetadataResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data MetadataResourceModel
 
-	// Read Terraform plan data into the model
+	// Read Terraform plan data into the model, and the write-only value from the
+	// configuration, as it is never part of the plan
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("value_wo"), &data.ValueWO)...)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -174,21 +369,41 @@ func (r *MetadataResource) Update(ctx context.Context, req resource.UpdateReques
 	// Update the metadata
 	updateReq := client.UpdateMetadataRequest{}
 
-	path := data.Path.ValueString()
+	// A configured ttl restarts on every update; without ttl or expires_at, a TTL of 0
+	// removes any expiry
+	if !data.TTL.IsNull() {
+		ttl, err := ttlSeconds(data.TTL)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ttl"), "Invalid Duration", err.Error())
+			return
+		}
+		updateReq.TTL = &ttl
+	} else if expiresAt, ok := data.expiresAt(); ok {
+		updateReq.ExpiresAt = &expiresAt
+	} else {
+		noTTL := 0
+		updateReq.TTL = &noTTL
+	}
+
+	path := data.Path.Canonical()
 	updateReq.Path = &path
 
-	value := data.Value.ValueString()
+	value := data.value()
 	updateReq.Value = &value
 
+	secret := data.Sensitive.ValueBool()
+	updateReq.Secret = &secret
+
 	metadata, err := r.client.UpdateMetadata(ctx, data.ID.ValueString(), updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "update", "metadata", data.ID.ValueString(), err)
 		return
 	}
 
 	// Update the model with the response data
-	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	data.Path = NewMetadataPathValue(metadata.Path)
+	data.setValue(metadata)
+	data.setExpiresAt(metadata.ExpiresAt)
 	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
 
 	// Save updated data into Terraform state
@@ -212,7 +427,7 @@ func (r *MetadataResource) Delete(ctx context.Context, req resource.DeleteReques
 			// Already gone; deletion is idempotent
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "delete", "metadata", data.ID.ValueString(), err)
 		return
 	}
 }
@@ -220,21 +435,102 @@ func (r *MetadataResource) Delete(ctx context.Context, req resource.DeleteReques
 func (r *MetadataResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Use the ID from the import request
 	data := MetadataResourceModel{
-		ID: types.StringValue(req.ID),
+		ID:             types.StringValue(req.ID),
+		Value:          types.StringNull(),
+		ValueJSON:      jsontypes.NewNormalizedNull(),
+		ValueWO:        types.StringNull(),
+		ValueWOVersion: types.Int64Null(),
+		TTL:            types.StringNull(),
+		ExpiresAt:      types.StringNull(),
 	}
 
 	// Read the metadata to populate other fields
 	metadata, err := r.client.GetMetadata(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to import metadata, got error: %s", err))
+		addClientError(&resp.Diagnostics, "import", "metadata", req.ID, err)
 		return
 	}
 
-	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	data.Path = NewMetadataPathValue(metadata.Path)
+	// Secrets are imported without their value, which is then expected in value_wo
+	if !metadata.Secret {
+		data.Value = types.StringValue(metadata.Value)
+	}
+	data.setValue(metadata)
+	data.setExpiresAt(metadata.ExpiresAt)
 	data.CreatedAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
 	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
 
 	// Save imported data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// value returns the configured metadata value, from value_json or value_wo when one
+// of them is set.
+func (m *MetadataResourceModel) value() string {
+	if !m.ValueJSON.IsNull() {
+		return m.ValueJSON.ValueString()
+	}
+	if !m.ValueWO.IsNull() {
+		return m.ValueWO.ValueString()
+	}
+	return m.Value.ValueString()
+}
+
+// setValue stores metadata returned by the API in the model. The value goes into
+// whichever of value and value_json the model uses; with value_wo, only its digest is
+// kept, and value_wo itself is cleared so that it never reaches the state.
+func (m *MetadataResourceModel) setValue(metadata *client.Metadata) {
+	switch {
+	case !m.ValueJSON.IsNull():
+		m.ValueJSON = jsontypes.NewNormalizedValue(metadata.Value)
+	case !m.Value.IsNull():
+		m.Value = types.StringValue(metadata.Value)
+	}
+	m.ValueWO = types.StringNull()
+	m.Sensitive = types.BoolValue(metadata.Secret)
+	m.ValueSHA256 = types.StringValue(metadata.ValueSHA256)
+	m.Version = types.Int64Value(int64(metadata.Version))
+}
+
+// ttlSeconds returns a configured ttl in seconds, as sent to the API.
+func ttlSeconds(value types.String) (int, error) {
+	ttl, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		return 0, fmt.Errorf("ttl must be a duration such as \"24h\", got error: %s", err)
+	}
+	if ttl < time.Second || ttl%time.Second != 0 {
+		return 0, fmt.Errorf("ttl must be a positive whole number of seconds, got %s", ttl)
+	}
+	return int(ttl / time.Second), nil
+}
+
+// expiresAt returns the planned expires_at, if it is known and set.
+func (m *MetadataResourceModel) expiresAt() (time.Time, bool) {
+	if m.ExpiresAt.IsNull() || m.ExpiresAt.IsUnknown() {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, m.ExpiresAt.ValueString())
+	return t, err == nil
+}
+
+// setExpiresAt stores the expiry returned by the API in the model. A timestamp already
+// in the model is kept when it denotes the same instant, so that a configured
+// expires_at with a UTC offset does not show up as drift.
+func (m *MetadataResourceModel) setExpiresAt(expiresAt *time.Time) {
+	if expiresAt == nil {
+		m.ExpiresAt = types.StringNull()
+		return
+	}
+	if current, ok := m.expiresAt(); ok && current.Equal(*expiresAt) {
+		return
+	}
+	m.ExpiresAt = types.StringValue(expiresAt.Format("2006-01-02T15:04:05Z07:00"))
+}
+
+// valueSHA256 returns the hex-encoded SHA-256 digest of a metadata value, as reported
+// by the server in value_sha256.
+func valueSHA256(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
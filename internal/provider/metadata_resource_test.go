@@ -0,0 +1,547 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+
+	"github.com/terraform-provider-dirt/internal/client"
+	"github.com/terraform-provider-dirt/internal/dirttest"
+)
+
+func TestAccMetadataResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccMetadataResourceConfig("tf-acc-config/version", "1.0.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "path", "tf-acc-config/version"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value", "1.0.0"),
+					resource.TestCheckResourceAttrSet("dirt_metadata.test", "id"),
+					resource.TestCheckResourceAttrSet("dirt_metadata.test", "created_at"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "version", "1"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "dirt_metadata.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update value and path in place
+			{
+				Config: testAccMetadataResourceConfig("tf-acc-config/release", "1.1.0"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "path", "tf-acc-config/release"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value", "1.1.0"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "version", "2"),
+					testAccCaptureID("dirt_metadata.test", &id),
+				),
+			},
+			// Out-of-band deletion is detected and the entry recreated
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteMetadata(context.Background(), id); err != nil {
+						t.Fatalf("deleting metadata out of band: %s", err)
+					}
+				},
+				Config: testAccMetadataResourceConfig("tf-acc-config/release", "1.1.0"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_metadata.test", &id),
+			},
+		},
+	})
+}
+
+func testAccMetadataResourceConfig(path, value string) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path  = %[1]q
+  value = %[2]q
+}
+`, path, value)
+}
+
+func TestAccMetadataResource_valueJSON(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceJSONConfig(`jsonencode({ enabled = true, rollout = { percent = 25, regions = ["eu", "us"] } })`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value_json", `{"enabled":true,"rollout":{"percent":25,"regions":["eu","us"]}}`),
+					resource.TestCheckNoResourceAttr("dirt_metadata.test", "value"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "value", `{"enabled":true,"rollout":{"percent":25,"regions":["eu","us"]}}`),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.dirt_metadata.test", tfjsonpath.New("value_json"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"enabled": knownvalue.Bool(true),
+						"rollout": knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"percent": knownvalue.Int64Exact(25),
+							"regions": knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("eu"), knownvalue.StringExact("us")}),
+						}),
+					})),
+				},
+			},
+			// The stored document being reformatted, e.g. by another client, is not drift
+			{
+				PreConfig: func() {
+					m, err := env.Client.GetMetadataByPath(context.Background(), "tf-acc-json/flags")
+					if err != nil {
+						t.Fatalf("reading metadata: %s", err)
+					}
+					value := "{\n  \"rollout\": { \"regions\": [\"eu\", \"us\"], \"percent\": 25 },\n  \"enabled\": true\n}"
+					if _, err := env.Client.UpdateMetadata(context.Background(), m.ID, client.UpdateMetadataRequest{Value: &value}); err != nil {
+						t.Fatalf("reformatting metadata out of band: %s", err)
+					}
+				},
+				Config: testAccMetadataResourceJSONConfig(`jsonencode({ enabled = true, rollout = { percent = 25, regions = ["eu", "us"] } })`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("dirt_metadata.test", "value_json", `{"enabled":true,"rollout":{"percent":25,"regions":["eu","us"]}}`),
+			},
+			// Content changes are
+			{
+				Config: testAccMetadataResourceJSONConfig(`jsonencode({ enabled = true, rollout = { percent = 50, regions = ["eu", "us"] } })`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("dirt_metadata.test", "value_json", `{"enabled":true,"rollout":{"percent":50,"regions":["eu","us"]}}`),
+			},
+			// Switching to a plain value
+			{
+				Config: testAccMetadataResourceConfig("tf-acc-json/flags", "not json"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value", "not json"),
+					resource.TestCheckNoResourceAttr("dirt_metadata.test", "value_json"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_valueExactlyOne(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_metadata" "test" {
+  path       = "tf-acc-json/both"
+  value      = "{}"
+  value_json = "{}"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured:\s+\[value,value_json,value_wo\]`),
+			},
+			{
+				Config: `
+resource "dirt_metadata" "test" {
+  path = "tf-acc-json/none"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured:\s+\[value,value_json,value_wo\]`),
+			},
+			{
+				Config: `
+resource "dirt_metadata" "test" {
+  path             = "tf-acc-json/version"
+  value            = "{}"
+  value_wo_version = 1
+}
+`,
+				ExpectError: regexp.MustCompile(`Attribute "value_wo" must be specified when "value_wo_version" is\s+specified`),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_valueWO(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceWOConfig("s3cr3t", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("dirt_metadata.test", "value_wo"),
+					resource.TestCheckNoResourceAttr("dirt_metadata.test", "value"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "sensitive", "true"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value_sha256", valueSHA256("s3cr3t")),
+					testAccCheckMetadataValue(env, "tf-acc-wo/api_key", "s3cr3t"),
+				),
+			},
+			// Secrets are imported without their value
+			{
+				ResourceName:            "dirt_metadata.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"value_wo_version"},
+			},
+			// A value changed on the server is detected by its digest and overwritten
+			{
+				PreConfig: func() {
+					m, err := env.Client.GetMetadataByPath(context.Background(), "tf-acc-wo/api_key")
+					if err != nil {
+						t.Fatalf("reading metadata: %s", err)
+					}
+					value := "tampered"
+					if _, err := env.Client.UpdateMetadata(context.Background(), m.ID, client.UpdateMetadataRequest{Value: &value}); err != nil {
+						t.Fatalf("changing metadata out of band: %s", err)
+					}
+				},
+				Config: testAccMetadataResourceWOConfig("s3cr3t", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckMetadataValue(env, "tf-acc-wo/api_key", "s3cr3t"),
+			},
+			// So is a value changed in the configuration
+			{
+				Config: testAccMetadataResourceWOConfig("rotated", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value_sha256", valueSHA256("rotated")),
+					testAccCheckMetadataValue(env, "tf-acc-wo/api_key", "rotated"),
+				),
+			},
+			// Bumping the version alone sends the value again
+			{
+				Config: testAccMetadataResourceWOConfig("rotated", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("dirt_metadata.test", "value_wo_version", "2"),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_sensitive(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceSensitiveConfig(true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectSensitiveValue("dirt_metadata.test", tfjsonpath.New("value")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "sensitive", "true"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "value_sha256", valueSHA256("db.internal")),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "sensitive", "true"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "sensitive_value", "db.internal"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata.test", "value"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata.test", "value_json"),
+				),
+			},
+			{
+				Config: testAccMetadataResourceSensitiveConfig(false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "sensitive", "false"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "sensitive", "false"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "value", "db.internal"),
+					resource.TestCheckNoResourceAttr("data.dirt_metadata.test", "sensitive_value"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_ttl(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceExpiryConfig(`ttl = "1h"`) + testAccMetadataDataSourceExpiryConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "ttl", "1h"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "expires_at", "2025-01-01T01:00:00Z"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "expires_at", "2025-01-01T01:00:00Z"),
+				),
+			},
+			{
+				ResourceName:            "dirt_metadata.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"ttl"},
+			},
+			// A timestamp with an offset is kept as configured
+			{
+				Config: testAccMetadataResourceExpiryConfig(`expires_at = "2025-01-01T03:00:00+01:00"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("dirt_metadata.test", "ttl"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "expires_at", "2025-01-01T03:00:00+01:00"),
+				),
+			},
+			// Removing both removes the expiry
+			{
+				Config: testAccMetadataResourceExpiryConfig(""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("dirt_metadata.test", tfjsonpath.New("expires_at"), knownvalue.Null()),
+					},
+				},
+				Check: resource.TestCheckNoResourceAttr("dirt_metadata.test", "expires_at"),
+			},
+			{
+				Config: testAccMetadataResourceExpiryConfig(`ttl = "30m"`),
+				Check:  resource.TestCheckResourceAttr("dirt_metadata.test", "expires_at", "2025-01-01T00:30:00Z"),
+			},
+			// Once expired, the entry is removed from state with a warning and created again
+			{
+				PreConfig: func() {
+					dirttest.AdvanceClock(t, env.Client, time.Hour)
+				},
+				Config: testAccMetadataResourceExpiryConfig(`ttl = "30m"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_metadata.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "version", "1"),
+					resource.TestCheckResourceAttr("dirt_metadata.test", "expires_at", "2025-01-01T01:30:00Z"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_invalidExpiry(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccMetadataResourceExpiryConfig(`ttl = "soon"`),
+				ExpectError: regexp.MustCompile(`ttl must be a duration such as "24h"`),
+			},
+			{
+				Config:      testAccMetadataResourceExpiryConfig(`ttl = "1500ms"`),
+				ExpectError: regexp.MustCompile(`ttl must be a positive whole number of seconds, got 1.5s`),
+			},
+			{
+				Config:      testAccMetadataResourceExpiryConfig(`expires_at = "tomorrow"`),
+				ExpectError: regexp.MustCompile(`expires_at must be an RFC 3339 timestamp`),
+			},
+			{
+				Config: testAccMetadataResourceExpiryConfig(`
+  ttl        = "1h"
+  expires_at = "2025-01-02T00:00:00Z"
+`),
+				ExpectError: regexp.MustCompile(`These attributes cannot be configured together:\s+\[ttl,expires_at\]`),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_pathNormalization(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The configured spelling is kept, and the canonical path stored
+			{
+				Config: `
+resource "dirt_metadata" "test" {
+  path  = "/tf-acc-path//app/key/"
+  value = "v"
+}
+
+data "dirt_metadata" "test" {
+  path = " tf-acc-path/app/key"
+
+  depends_on = [dirt_metadata.test]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_metadata.test", "path", "/tf-acc-path//app/key/"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.test", "path", " tf-acc-path/app/key"),
+					resource.TestCheckResourceAttrPair("data.dirt_metadata.test", "id", "dirt_metadata.test", "id"),
+					testAccCheckMetadataValue(env, "tf-acc-path/app/key", "v"),
+				),
+			},
+			{
+				ResourceName:            "dirt_metadata.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"path"},
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_invalidPath(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccMetadataResourceConfig("tf-acc-path/my key", "v"),
+				ExpectError: regexp.MustCompile(`path segment "my key" must only contain letters, digits, '_', '.' and\s+'-'`),
+			},
+			{
+				Config:      testAccMetadataResourceConfig("tf-acc-path/../key", "v"),
+				ExpectError: regexp.MustCompile(`must not contain "\.\." segments`),
+			},
+			{
+				Config:      testAccMetadataResourceConfig(strings.Repeat("tf-acc-path/", 16)+"key", "v"),
+				ExpectError: regexp.MustCompile(`path must have at most 16 segments, got 17`),
+			},
+			{
+				Config:      testAccMetadataResourceConfig("//", "v"),
+				ExpectError: regexp.MustCompile(`path must not be empty`),
+			},
+			{
+				Config: `
+data "dirt_metadata" "test" {
+  path = "tf-acc-path/key?"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Metadata Path`),
+			},
+		},
+	})
+}
+
+// testAccCheckMetadataValue checks the value stored on the server, which the state
+// does not hold for write-only values.
+func testAccCheckMetadataValue(env *testAccEnv, path, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		m, err := env.Client.GetMetadataByPath(context.Background(), path)
+		if err != nil {
+			return fmt.Errorf("reading metadata %q: %w", path, err)
+		}
+		if m.Value != want {
+			return fmt.Errorf("metadata %q has value %q, want %q", path, m.Value, want)
+		}
+		return nil
+	}
+}
+
+func testAccMetadataResourceWOConfig(value string, version int) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path             = "tf-acc-wo/api_key"
+  value_wo         = %[1]q
+  value_wo_version = %[2]d
+  sensitive        = true
+}
+`, value, version)
+}
+
+func testAccMetadataResourceSensitiveConfig(sensitive bool) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path      = "tf-acc-sensitive/db_host"
+  value     = "db.internal"
+  sensitive = %[1]t
+}
+
+data "dirt_metadata" "test" {
+  path = dirt_metadata.test.path
+
+  depends_on = [dirt_metadata.test]
+}
+`, sensitive)
+}
+
+func testAccMetadataResourceJSONConfig(valueJSON string) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path       = "tf-acc-json/flags"
+  value_json = %[1]s
+}
+
+data "dirt_metadata" "test" {
+  path = dirt_metadata.test.path
+}
+`, valueJSON)
+}
+
+func testAccMetadataResourceExpiryConfig(expiry string) string {
+	return fmt.Sprintf(`
+resource "dirt_metadata" "test" {
+  path  = "tf-acc-ttl/session"
+  value = "token"
+  %s
+}
+`, expiry)
+}
+
+const testAccMetadataDataSourceExpiryConfig = `
+data "dirt_metadata" "test" {
+  path = dirt_metadata.test.path
+}
+`
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ObjectDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &ObjectDataSource{}
+
+func NewObjectDataSource() datasource.DataSource {
+	return &ObjectDataSource{}
+}
+
+// ObjectDataSource defines the data source implementation.
+type ObjectDataSource struct {
+	client *client.Client
+}
+
+// ObjectDataSourceModel describes the data source data model.
+type ObjectDataSourceModel struct {
+	BucketID      types.String `tfsdk:"bucket_id"`
+	ID            types.String `tfsdk:"id"`
+	Path          types.String `tfsdk:"path"`
+	Content       types.String `tfsdk:"content"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	Size          types.Int64  `tfsdk:"size"`
+	ContentSHA256 types.String `tfsdk:"content_sha256"`
+	CreatedAt     types.String `tfsdk:"created_at"`
+	UpdatedAt     types.String `tfsdk:"updated_at"`
+}
+
+func (d *ObjectDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_object"
+}
+
+func (d *ObjectDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud object data source. Reads an object stored in a bucket, looked up by `path` or by `id`; exactly one of them must be set.",
+
+		Attributes: map[string]schema.Attribute{
+			"bucket_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the bucket containing the object",
+				Required:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Object identifier",
+				Optional:            true,
+				Computed:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Object path within the bucket",
+				Optional:            true,
+				Computed:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "Object content decoded as UTF-8; null when the content is not valid UTF-8, use `content_base64` for binary objects",
+				Computed:            true,
+			},
+			"content_base64": schema.StringAttribute{
+				MarkdownDescription: "Object content, base64-encoded",
+				Computed:            true,
+			},
+			"size": schema.Int64Attribute{
+				MarkdownDescription: "Object size in bytes",
+				Computed:            true,
+			},
+			"content_sha256": schema.StringAttribute{
+				MarkdownDescription: "Hex-encoded SHA-256 hash of the object content",
+				Computed:            true,
+			},
+			"created_at": schema.StringAttribute{
+				MarkdownDescription: "Object creation timestamp",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Object last updated timestamp",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ObjectDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("path"),
+		),
+	}
+}
+
+func (d *ObjectDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ObjectDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ObjectDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucketID := data.BucketID.ValueString()
+	var obj *client.Object
+
+	if !data.ID.IsNull() {
+		// Get the object from the API
+		o, err := d.client.GetObject(ctx, bucketID, data.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "read", "object", data.ID.ValueString(), err)
+			return
+		}
+		obj = o
+	} else {
+		objectPath := data.Path.ValueString()
+
+		// Look the object up by path
+		objects, err := d.client.ListObjects(ctx, bucketID)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "list", "objects", bucketID, err)
+			return
+		}
+
+		for i := range objects {
+			if objects[i].Path == objectPath {
+				obj = &objects[i]
+				break
+			}
+		}
+		if obj == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("path"), "Object Not Found",
+				fmt.Sprintf("Bucket %q has no object at path %q.", bucketID, objectPath))
+			return
+		}
+	}
+
+	content, err := base64.StdEncoding.DecodeString(obj.Content)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Object Content",
+			fmt.Sprintf("The API returned content for object %q that is not base64-encoded: %s", obj.ID, err))
+		return
+	}
+
+	// Update the model with the API response data
+	data.ID = types.StringValue(obj.ID)
+	data.BucketID = types.StringValue(obj.BucketID)
+	data.Path = types.StringValue(obj.Path)
+	data.ContentBase64 = types.StringValue(obj.Content)
+	data.Content = types.StringNull()
+	if utf8.Valid(content) {
+		data.Content = types.StringValue(string(content))
+	}
+	data.Size = types.Int64Value(int64(len(content)))
+	data.ContentSHA256 = types.StringValue(contentSHA256(content))
+	data.CreatedAt = types.StringValue(obj.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	data.UpdatedAt = types.StringValue(obj.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// contentSHA256 returns the hex-encoded SHA-256 hash of decoded object content.
+func contentSHA256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccObjectDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_bucket" "test" {
+  name = "tf-acc-object-ds"
+}
+
+resource "dirt_object" "config" {
+  bucket_id      = dirt_bucket.test.id
+  path           = "config/app.json"
+  content_base64 = base64encode("{\"greeting\":\"héllo\"}")
+}
+
+resource "dirt_object" "binary" {
+  bucket_id      = dirt_bucket.test.id
+  path           = "logo.bin"
+  content_base64 = "/9j/AA=="
+}
+
+data "dirt_object" "by_path" {
+  bucket_id = dirt_bucket.test.id
+  path      = dirt_object.config.path
+}
+
+data "dirt_object" "by_id" {
+  bucket_id = dirt_bucket.test.id
+  id        = dirt_object.binary.id
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_object.by_path", "id", "dirt_object.config", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_object.by_path", "bucket_id", "dirt_bucket.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_object.by_path", "path", "config/app.json"),
+					resource.TestCheckResourceAttr("data.dirt_object.by_path", "content", `{"greeting":"héllo"}`),
+					resource.TestCheckResourceAttrPair("data.dirt_object.by_path", "content_base64", "dirt_object.config", "content_base64"),
+					resource.TestCheckResourceAttr("data.dirt_object.by_path", "size", "21"),
+					resource.TestCheckResourceAttr("data.dirt_object.by_path", "content_sha256", "7b5a3765d9c507d10d4909802de57167112ba785008952b38ef3554fa48eb669"),
+					resource.TestCheckResourceAttrPair("data.dirt_object.by_path", "created_at", "dirt_object.config", "created_at"),
+					resource.TestCheckResourceAttrSet("data.dirt_object.by_path", "updated_at"),
+
+					resource.TestCheckResourceAttr("data.dirt_object.by_id", "path", "logo.bin"),
+					resource.TestCheckNoResourceAttr("data.dirt_object.by_id", "content"),
+					resource.TestCheckResourceAttr("data.dirt_object.by_id", "content_base64", "/9j/AA=="),
+					resource.TestCheckResourceAttr("data.dirt_object.by_id", "size", "4"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccObjectDataSource_notFound(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_bucket" "test" {
+  name = "tf-acc-object-ds-missing"
+}
+
+data "dirt_object" "test" {
+  bucket_id = dirt_bucket.test.id
+  path      = "missing.txt"
+}
+`,
+				ExpectError: regexp.MustCompile(`has no object at path "missing.txt"`),
+			},
+		},
+	})
+}
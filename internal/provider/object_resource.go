@@ -128,7 +128,7 @@ func (r *ObjectResource) Create(ctx context.Context, req resource.CreateRequest,
 	createReq := client.CreateObjectRequest{ BucketID: bucketID, Path: path, Content: contentB64 }
 	obj, err := r.client.CreateObject(ctx, bucketID, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create object, got error: %s", err))
+		addClientError(&resp.Diagnostics, "create", "object", path, err)
 		return
 	}
 
@@ -156,7 +156,7 @@ func (r *ObjectResource) Read(ctx context.Context, req resource.ReadRequest, res
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read object, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "object", data.ID.ValueString(), err)
 		return
 	}
 
@@ -192,7 +192,7 @@ func (r *ObjectResource) Update(ctx context.Context, req resource.UpdateRequest,
 
 	obj, err := r.client.UpdateObject(ctx, data.BucketID.ValueString(), data.ID.ValueString(), updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update object, got error: %s", err))
+		addClientError(&resp.Diagnostics, "update", "object", data.ID.ValueString(), err)
 		return
 	}
 
@@ -215,7 +215,7 @@ func (r *ObjectResource) Delete(ctx context.Context, req resource.DeleteRequest,
 			// Already deleted; success
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete object, got error: %s", err))
+		addClientError(&resp.Diagnostics, "delete", "object", data.ID.ValueString(), err)
 		return
 	}
 }
@@ -233,7 +233,7 @@ func (r *ObjectResource) ImportState(ctx context.Context, req resource.ImportSta
 
 	obj, err := r.client.GetObject(ctx, bucketID, objectID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to import object, got error: %s", err))
+		addClientError(&resp.Diagnostics, "import", "object", req.ID, err)
 		return
 	}
 
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccObjectResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id, bucketID string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccObjectResourceConfig("first", "index.html", "hello"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dirt_object.test", "bucket_id", "dirt_bucket.first", "id"),
+					resource.TestCheckResourceAttr("dirt_object.test", "path", "index.html"),
+					resource.TestCheckResourceAttr("dirt_object.test", "content_base64", "aGVsbG8="),
+					resource.TestCheckResourceAttrSet("dirt_object.test", "id"),
+					resource.TestCheckResourceAttrSet("dirt_object.test", "created_at"),
+				),
+			},
+			// ImportState testing with the {bucket_id}/{object_id} format
+			{
+				ResourceName:      "dirt_object.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccObjectImportID("dirt_object.test"),
+				ImportStateVerify: true,
+			},
+			// Update path and content in place
+			{
+				Config: testAccObjectResourceConfig("first", "site/index.html", "hello, world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_object.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_object.test", "path", "site/index.html"),
+					resource.TestCheckResourceAttr("dirt_object.test", "content_base64", "aGVsbG8sIHdvcmxk"),
+					testAccCaptureID("dirt_object.test", &id),
+				),
+			},
+			// Moving to another bucket replaces the object
+			{
+				Config: testAccObjectResourceConfig("second", "site/index.html", "hello, world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_object.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("dirt_object.test", "bucket_id", "dirt_bucket.second", "id"),
+					testAccCheckIDChanged("dirt_object.test", &id),
+					testAccCaptureID("dirt_object.test", &id),
+					testAccCaptureID("dirt_bucket.second", &bucketID),
+				),
+			},
+			// Out-of-band deletion is detected and the object recreated
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteObject(context.Background(), bucketID, id); err != nil {
+						t.Fatalf("deleting object out of band: %s", err)
+					}
+				},
+				Config: testAccObjectResourceConfig("second", "site/index.html", "hello, world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_object.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_object.test", &id),
+			},
+		},
+	})
+}
+
+// testAccObjectImportID returns the {bucket_id}/{object_id} import ID of the object at address.
+func testAccObjectImportID(address string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found in state", address)
+		}
+		return rs.Primary.Attributes["bucket_id"] + "/" + rs.Primary.ID, nil
+	}
+}
+
+// testAccObjectResourceConfig declares two buckets and an object in the bucket named by
+// bucket.
+func testAccObjectResourceConfig(bucket, path, content string) string {
+	return fmt.Sprintf(`
+resource "dirt_bucket" "first" {
+  name = "tf-acc-object-first"
+}
+
+resource "dirt_bucket" "second" {
+  name = "tf-acc-object-second"
+}
+
+resource "dirt_object" "test" {
+  bucket_id      = dirt_bucket.%[1]s.id
+  path           = %[2]q
+  content_base64 = base64encode(%[3]q)
+}
+`, bucket, path, content)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ObjectsDataSource{}
+
+func NewObjectsDataSource() datasource.DataSource {
+	return &ObjectsDataSource{}
+}
+
+// ObjectsDataSource defines the data source implementation.
+type ObjectsDataSource struct {
+	client *client.Client
+}
+
+// ObjectsDataSourceModel describes the data source data model.
+type ObjectsDataSourceModel struct {
+	BucketID       types.String       `tfsdk:"bucket_id"`
+	Prefix         types.String       `tfsdk:"prefix"`
+	Delimiter      types.String       `tfsdk:"delimiter"`
+	IDs            []types.String     `tfsdk:"ids"`
+	Objects        []ObjectsItemModel `tfsdk:"objects"`
+	CommonPrefixes []types.String     `tfsdk:"common_prefixes"`
+}
+
+// ObjectsItemModel describes a single object in the list.
+type ObjectsItemModel struct {
+	ID            types.String `tfsdk:"id"`
+	Path          types.String `tfsdk:"path"`
+	Size          types.Int64  `tfsdk:"size"`
+	ContentSHA256 types.String `tfsdk:"content_sha256"`
+	CreatedAt     types.String `tfsdk:"created_at"`
+	UpdatedAt     types.String `tfsdk:"updated_at"`
+}
+
+func (d *ObjectsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objects"
+}
+
+func (d *ObjectsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud objects data source. Lists the objects of a bucket whose path starts with `prefix`. " +
+			"With a `delimiter`, paths containing the delimiter after the prefix are rolled up into `common_prefixes` " +
+			"instead, like directories: `prefix = \"site/\"` and `delimiter = \"/\"` list `site/index.html` but only report `site/css/` for `site/css/main.css`.",
+
+		Attributes: map[string]schema.Attribute{
+			"bucket_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the bucket to list",
+				Required:            true,
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only return objects whose path starts with this prefix",
+				Optional:            true,
+			},
+			"delimiter": schema.StringAttribute{
+				MarkdownDescription: "Group paths that contain this string after `prefix` into `common_prefixes`, usually `/`",
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "IDs of the listed objects, in the same order as `objects`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"objects": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching objects sorted by path. Content is not included; read it with the `dirt_object` data source",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Object identifier",
+							Computed:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Object path within the bucket",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "Object size in bytes",
+							Computed:            true,
+						},
+						"content_sha256": schema.StringAttribute{
+							MarkdownDescription: "Hex-encoded SHA-256 hash of the object content",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Object creation timestamp",
+							Computed:            true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Object last updated timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"common_prefixes": schema.ListAttribute{
+				MarkdownDescription: "Sorted, distinct path prefixes up to and including the first `delimiter` after `prefix`; empty without a delimiter",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ObjectsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ObjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ObjectsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucketID := data.BucketID.ValueString()
+	prefix := data.Prefix.ValueString()
+	delimiter := data.Delimiter.ValueString()
+
+	// Get the objects from the API
+	objects, err := d.client.ListObjects(ctx, bucketID)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "objects", bucketID, err)
+		return
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path })
+
+	data.IDs = []types.String{}
+	data.Objects = []ObjectsItemModel{}
+	data.CommonPrefixes = []types.String{}
+	seen := map[string]bool{}
+
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Path, prefix) {
+			continue
+		}
+
+		if delimiter != "" {
+			if i := strings.Index(obj.Path[len(prefix):], delimiter); i >= 0 {
+				common := obj.Path[:len(prefix)+i+len(delimiter)]
+				if !seen[common] {
+					seen[common] = true
+					data.CommonPrefixes = append(data.CommonPrefixes, types.StringValue(common))
+				}
+				continue
+			}
+		}
+
+		content, err := base64.StdEncoding.DecodeString(obj.Content)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid Object Content",
+				fmt.Sprintf("The API returned content for object %q that is not base64-encoded: %s", obj.ID, err))
+			return
+		}
+
+		data.IDs = append(data.IDs, types.StringValue(obj.ID))
+		data.Objects = append(data.Objects, ObjectsItemModel{
+			ID:            types.StringValue(obj.ID),
+			Path:          types.StringValue(obj.Path),
+			Size:          types.Int64Value(int64(len(content))),
+			ContentSHA256: types.StringValue(contentSHA256(content)),
+			CreatedAt:     types.StringValue(obj.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+			UpdatedAt:     types.StringValue(obj.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccObjectsDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_bucket" "test" {
+  name = "tf-acc-objects-ds"
+}
+
+resource "dirt_object" "test" {
+  count = 3
+
+  bucket_id      = dirt_bucket.test.id
+  path           = ["site/css/main.css", "site/css/print.css", "site/js/app.js"][count.index]
+  content_base64 = base64encode("asset ${count.index}")
+}
+
+resource "dirt_object" "index" {
+  bucket_id      = dirt_bucket.test.id
+  path           = "site/index.html"
+  content_base64 = base64encode("<h1>hello</h1>")
+}
+
+resource "dirt_object" "readme" {
+  bucket_id      = dirt_bucket.test.id
+  path           = "readme.txt"
+  content_base64 = base64encode("read me")
+}
+
+data "dirt_objects" "all" {
+  bucket_id = dirt_bucket.test.id
+
+  depends_on = [dirt_object.test, dirt_object.index, dirt_object.readme]
+}
+
+data "dirt_objects" "site" {
+  bucket_id = dirt_bucket.test.id
+  prefix    = "site/"
+  delimiter = "/"
+
+  depends_on = [dirt_object.test, dirt_object.index, dirt_object.readme]
+}
+
+data "dirt_objects" "css" {
+  bucket_id = dirt_bucket.test.id
+  prefix    = "site/css/"
+
+  depends_on = [dirt_object.test, dirt_object.index, dirt_object.readme]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "objects.#", "5"),
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "objects.0.path", "readme.txt"),
+					resource.TestCheckResourceAttrPair("data.dirt_objects.all", "objects.0.id", "dirt_object.readme", "id"),
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "objects.0.size", "7"),
+					resource.TestCheckResourceAttrSet("data.dirt_objects.all", "objects.0.content_sha256"),
+					resource.TestCheckResourceAttrSet("data.dirt_objects.all", "objects.0.created_at"),
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "objects.4.path", "site/js/app.js"),
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "ids.#", "5"),
+					resource.TestCheckResourceAttr("data.dirt_objects.all", "common_prefixes.#", "0"),
+
+					resource.TestCheckResourceAttr("data.dirt_objects.site", "objects.#", "1"),
+					resource.TestCheckResourceAttr("data.dirt_objects.site", "objects.0.path", "site/index.html"),
+					resource.TestCheckResourceAttrPair("data.dirt_objects.site", "ids.0", "dirt_object.index", "id"),
+					resource.TestCheckResourceAttr("data.dirt_objects.site", "common_prefixes.#", "2"),
+					resource.TestCheckResourceAttr("data.dirt_objects.site", "common_prefixes.0", "site/css/"),
+					resource.TestCheckResourceAttr("data.dirt_objects.site", "common_prefixes.1", "site/js/"),
+
+					resource.TestCheckResourceAttr("data.dirt_objects.css", "objects.#", "2"),
+					resource.TestCheckResourceAttr("data.dirt_objects.css", "objects.0.path", "site/css/main.css"),
+					resource.TestCheckResourceAttr("data.dirt_objects.css", "objects.1.path", "site/css/print.css"),
+				),
+			},
+		},
+	})
+}
@@ -7,14 +7,17 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-provider-dirt/internal/client"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ datasource.DataSource = &ProjectDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &ProjectDataSource{}
 
 func NewProjectDataSource() datasource.DataSource {
 	return &ProjectDataSource{}
@@ -39,15 +42,17 @@ func (d *ProjectDataSource) Metadata(ctx context.Context, req datasource.Metadat
 
 func (d *ProjectDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "DirtCloud project data source",
+		MarkdownDescription: "DirtCloud project data source. Looks up an existing project by `id` or by `name`; exactly one of them must be set.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				MarkdownDescription: "Project identifier",
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
 			},
 			"name": schema.StringAttribute{
 				MarkdownDescription: "Project name",
+				Optional:            true,
 				Computed:            true,
 			},
 			"created_at": schema.StringAttribute{
@@ -62,6 +67,15 @@ func (d *ProjectDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 	}
 }
 
+func (d *ProjectDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("name"),
+		),
+	}
+}
+
 func (d *ProjectDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -92,11 +106,32 @@ func (d *ProjectDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	// Get the project from the API
-	project, err := d.client.GetProject(ctx, data.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read project, got error: %s", err))
-		return
+	var project *client.Project
+
+	if !data.ID.IsNull() {
+		// Get the project from the API
+		p, err := d.client.GetProject(ctx, data.ID.ValueString())
+		if err != nil {
+			addClientError(&resp.Diagnostics, "read", "project", data.ID.ValueString(), err)
+			return
+		}
+		project = p
+	} else {
+		name := data.Name.ValueString()
+
+		// Look the project up by name
+		projects, err := d.client.ListProjects(ctx, name)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "list", "projects", "", err)
+			return
+		}
+
+		project = lookupByName(&resp.Diagnostics, "project", name, "", projects,
+			func(p client.Project) string { return p.Name },
+			func(p client.Project) string { return p.ID })
+		if project == nil {
+			return
+		}
 	}
 
 	// Update the model with the API response data
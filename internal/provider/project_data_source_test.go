@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProjectDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-project-ds"
+}
+
+data "dirt_project" "test" {
+  id = dirt_project.test.id
+}
+
+data "dirt_project" "by_name" {
+  name = dirt_project.test.name
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_project.test", "id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_project.test", "name", "tf-acc-project-ds"),
+					resource.TestCheckResourceAttrPair("data.dirt_project.test", "created_at", "dirt_project.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.dirt_project.test", "updated_at"),
+					resource.TestCheckResourceAttrPair("data.dirt_project.by_name", "id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttr("data.dirt_project.by_name", "name", "tf-acc-project-ds"),
+					resource.TestCheckResourceAttrPair("data.dirt_project.by_name", "created_at", "dirt_project.test", "created_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProjectDataSource_lookupErrors(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_project" "test" {
+  name = "tf-acc-project-ds-missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`No project is named "tf-acc-project-ds-missing"`),
+			},
+			{
+				Config: `
+data "dirt_project" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured: \[id,name\]`),
+			},
+			{
+				Config: `
+data "dirt_project" "test" {
+  id   = "proj-0001"
+  name = "tf-acc-project-ds"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured: \[id,name\]`),
+			},
+		},
+	})
+}
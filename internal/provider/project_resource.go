@@ -108,7 +108,7 @@ func (r *ProjectResource) Create(ctx context.Context, req resource.CreateRequest
 
 	project, err := r.client.CreateProject(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create project, got error: %s", err))
+		addClientError(&resp.Diagnostics, "create", "project", data.Name.ValueString(), err)
 		return
 	}
 
@@ -140,7 +140,7 @@ func (r *ProjectResource) Read(ctx context.Context, req resource.ReadRequest, re
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read project, got error: %s", err))
+		addClientError(&resp.Diagnostics, "read", "project", data.ID.ValueString(), err)
 		return
 	}
 
@@ -170,7 +170,7 @@ func (r *ProjectResource) Update(ctx context.Context, req resource.UpdateRequest
 
 	project, err := r.client.UpdateProject(ctx, data.ID.ValueString(), updateReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update project, got error: %s", err))
+		addClientError(&resp.Diagnostics, "update", "project", data.ID.ValueString(), err)
 		return
 	}
 
@@ -199,7 +199,7 @@ func (r *ProjectResource) Delete(ctx context.Context, req resource.DeleteRequest
 			// Already gone; success
 			return
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete project, got error: %s", err))
+		addClientError(&resp.Diagnostics, "delete", "project", data.ID.ValueString(), err)
 		return
 	}
 }
@@ -213,7 +213,7 @@ func (r *ProjectResource) ImportState(ctx context.Context, req resource.ImportSt
 	// Read the project to populate other fields
 	project, err := r.client.GetProject(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to import project, got error: %s", err))
+		addClientError(&resp.Diagnostics, "import", "project", req.ID, err)
 		return
 	}
 
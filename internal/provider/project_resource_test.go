@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+
+	"github.com/terraform-provider-dirt/internal/dirttest"
+)
+
+func TestAccProjectResource(t *testing.T) {
+	env := newTestAccEnv(t)
+	var id string
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProjectResourceConfig("tf-acc-project"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_project.test", "name", "tf-acc-project"),
+					resource.TestCheckResourceAttrSet("dirt_project.test", "id"),
+					resource.TestCheckResourceAttrSet("dirt_project.test", "created_at"),
+					resource.TestCheckResourceAttrSet("dirt_project.test", "updated_at"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "dirt_project.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccProjectResourceConfig("tf-acc-project-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_project.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_project.test", "name", "tf-acc-project-renamed"),
+					testAccCaptureID("dirt_project.test", &id),
+				),
+			},
+			// Out-of-band deletion is detected and the project recreated
+			{
+				PreConfig: func() {
+					if err := env.Client.DeleteProject(context.Background(), id); err != nil {
+						t.Fatalf("deleting project out of band: %s", err)
+					}
+				},
+				Config: testAccProjectResourceConfig("tf-acc-project-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("dirt_project.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testAccCheckIDChanged("dirt_project.test", &id),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// TestAccProjectResource_deterministic relies on the deterministic mode newTestAccEnv
+// enables: IDs count up from proj-0001 and the clock only moves when advanced.
+func TestAccProjectResource_deterministic(t *testing.T) {
+	env := newTestAccEnv(t, inProcessOnly())
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProjectResourceConfig("tf-acc-project-deterministic"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_project.test", "id", "proj-0001"),
+					resource.TestCheckResourceAttr("dirt_project.test", "created_at", "2025-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("dirt_project.test", "updated_at", "2025-01-01T00:00:00Z"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if now := dirttest.AdvanceClock(t, env.Client, 90*time.Minute); !now.Equal(time.Date(2025, time.January, 1, 1, 30, 0, 0, time.UTC)) {
+						t.Fatalf("clock advanced to %s, want 2025-01-01T01:30:00Z", now)
+					}
+				},
+				Config: testAccProjectResourceConfig("tf-acc-project-deterministic-renamed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dirt_project.test", "id", "proj-0001"),
+					resource.TestCheckResourceAttr("dirt_project.test", "created_at", "2025-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("dirt_project.test", "updated_at", "2025-01-01T01:30:00Z"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProjectResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "dirt_project" "test" {
+  name = %[1]q
+}
+`, name)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ProjectsDataSource{}
+
+func NewProjectsDataSource() datasource.DataSource {
+	return &ProjectsDataSource{}
+}
+
+// ProjectsDataSource defines the data source implementation.
+type ProjectsDataSource struct {
+	client *client.Client
+}
+
+// ProjectsDataSourceModel describes the data source data model.
+type ProjectsDataSourceModel struct {
+	Name         types.String             `tfsdk:"name"`
+	NameRegex    types.String             `tfsdk:"name_regex"`
+	CreatedSince types.String             `tfsdk:"created_since"`
+	CreatedUntil types.String             `tfsdk:"created_until"`
+	IDs          []types.String           `tfsdk:"ids"`
+	Projects     []ProjectDataSourceModel `tfsdk:"projects"`
+}
+
+func (d *ProjectsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_projects"
+}
+
+func (d *ProjectsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DirtCloud projects data source. Lists the projects matching all of the given filters, or all projects when none is set.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Only return projects with exactly this name",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return projects whose name matches this regular expression ([RE2 syntax](https://github.com/google/re2/wiki/Syntax))",
+				Optional:            true,
+			},
+			"created_since": schema.StringAttribute{
+				MarkdownDescription: "Only return projects created at or after this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"created_until": schema.StringAttribute{
+				MarkdownDescription: "Only return projects created at or before this RFC 3339 timestamp",
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "IDs of the matching projects, in the same order as `projects`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"projects": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching projects sorted by name, then ID",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Project identifier",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Project name",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Project creation timestamp",
+							Computed:            true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Project last updated timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ProjectsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ProjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ProjectsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := newListFilter(data.Name, data.NameRegex, data.CreatedSince, data.CreatedUntil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the projects from the API
+	projects, err := d.client.ListProjects(ctx, data.Name.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, "list", "projects", "", err)
+		return
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	data.IDs = []types.String{}
+	data.Projects = []ProjectDataSourceModel{}
+	for _, project := range projects {
+		if !filter.match(project.Name, project.CreatedAt) {
+			continue
+		}
+
+		data.IDs = append(data.IDs, types.StringValue(project.ID))
+		data.Projects = append(data.Projects, ProjectDataSourceModel{
+			ID:        types.StringValue(project.ID),
+			Name:      types.StringValue(project.Name),
+			CreatedAt: types.StringValue(project.CreatedAt.Format("2006-01-02T15:04:05Z07:00")),
+			UpdatedAt: types.StringValue(project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProjectsDataSource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "beta" {
+  name = "tf-acc-projects-ds-beta"
+}
+
+resource "dirt_project" "alpha" {
+  name = "tf-acc-projects-ds-alpha"
+}
+
+data "dirt_projects" "regex" {
+  name_regex = "^tf-acc-projects-ds-"
+
+  depends_on = [dirt_project.alpha, dirt_project.beta]
+}
+
+data "dirt_projects" "name" {
+  name = "tf-acc-projects-ds-beta"
+
+  depends_on = [dirt_project.alpha, dirt_project.beta]
+}
+
+data "dirt_projects" "since" {
+  name_regex    = "^tf-acc-projects-ds-"
+  created_since = dirt_project.alpha.created_at
+
+  depends_on = [dirt_project.beta]
+}
+
+data "dirt_projects" "until" {
+  name_regex    = "^tf-acc-projects-ds-"
+  created_until = "2000-01-01T00:00:00Z"
+
+  depends_on = [dirt_project.alpha, dirt_project.beta]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_projects.regex", "projects.#", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.regex", "projects.0.id", "dirt_project.alpha", "id"),
+					resource.TestCheckResourceAttr("data.dirt_projects.regex", "projects.0.name", "tf-acc-projects-ds-alpha"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.regex", "projects.0.created_at", "dirt_project.alpha", "created_at"),
+					resource.TestCheckResourceAttrSet("data.dirt_projects.regex", "projects.0.updated_at"),
+					resource.TestCheckResourceAttr("data.dirt_projects.regex", "projects.1.name", "tf-acc-projects-ds-beta"),
+					resource.TestCheckResourceAttr("data.dirt_projects.regex", "ids.#", "2"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.regex", "ids.0", "dirt_project.alpha", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.regex", "ids.1", "dirt_project.beta", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_projects.name", "projects.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.name", "ids.0", "dirt_project.beta", "id"),
+
+					resource.TestCheckResourceAttrPair("data.dirt_projects.since", "ids.0", "dirt_project.alpha", "id"),
+
+					resource.TestCheckResourceAttr("data.dirt_projects.until", "projects.#", "0"),
+					resource.TestCheckResourceAttr("data.dirt_projects.until", "ids.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProjectsDataSource_exactName(t *testing.T) {
+	env := newTestAccEnv(t, withoutNameFilter())
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-projects-ds-exact"
+}
+
+resource "dirt_project" "longer" {
+  name = "tf-acc-projects-ds-exact-longer"
+}
+
+data "dirt_projects" "test" {
+  name = dirt_project.test.name
+
+  depends_on = [dirt_project.longer]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_projects.test", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.dirt_projects.test", "ids.0", "dirt_project.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProjectsDataSource_invalidFilters(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_projects" "test" {
+  name_regex    = "tf-acc-("
+  created_since = "yesterday"
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)name_regex must be a valid regular expression.*created_since must be an RFC 3339 timestamp`),
+			},
+		},
+	})
+}
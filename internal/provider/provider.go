@@ -5,6 +5,7 @@ package provider
 
 import (
 	"context"
+	"net/http"
 	"os"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -26,6 +27,15 @@ type DirtProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// endpoint, when set, replaces the default endpoint used when neither the
+	// configuration nor DIRT_ENDPOINT sets one. Acceptance tests point it at their
+	// in-process server.
+	endpoint string
+
+	// httpClient, when set, replaces the API client's HTTP client. Acceptance tests
+	// use it to record and replay API traffic.
+	httpClient *http.Client
 }
 
 // DirtProviderModel describes the provider data model.
@@ -67,6 +77,9 @@ func (p *DirtProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 
 	// Set defaults and get values from environment if not configured
 	endpoint := "http://localhost:8080/v1"
+	if p.endpoint != "" {
+		endpoint = p.endpoint
+	}
 	if !data.Endpoint.IsNull() {
 		endpoint = data.Endpoint.ValueString()
 	} else if envEndpoint := os.Getenv("DIRT_ENDPOINT"); envEndpoint != "" {
@@ -85,6 +98,10 @@ func (p *DirtProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	if token != "" {
 		dirtClient.Token = token
 	}
+	dirtClient.UserAgent = "terraform-provider-dirt/" + p.version
+	if p.httpClient != nil {
+		dirtClient.HTTPClient = p.httpClient
+	}
 
 	// Make the client available to resources and data sources
 	resp.DataSourceData = dirtClient
@@ -96,6 +113,7 @@ func (p *DirtProvider) Resources(ctx context.Context) []func() resource.Resource
 		NewProjectResource,
 		NewInstanceResource,
 		NewMetadataResource,
+		NewMetadataLockResource,
 		NewBucketResource,
 		NewObjectResource,
 	}
@@ -112,6 +130,15 @@ func (p *DirtProvider) DataSources(ctx context.Context) []func() datasource.Data
 		NewProjectDataSource,
 		NewInstanceDataSource,
 		NewMetadataDataSource,
+		NewMetadataPrefixDataSource,
+		NewMetadataHistoryDataSource,
+		NewEventsDataSource,
+		NewBucketDataSource,
+		NewBucketsDataSource,
+		NewObjectDataSource,
+		NewObjectsDataSource,
+		NewProjectsDataSource,
+		NewInstancesDataSource,
 	}
 }
 
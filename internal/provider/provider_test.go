@@ -2,3 +2,480 @@
 // SPDX-License-Identifier: MPL-2.0
 
 package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/terraform-provider-dirt/internal/client"
+	"github.com/terraform-provider-dirt/internal/dirttest"
+	"github.com/terraform-provider-dirt/internal/server"
+	"github.com/terraform-provider-dirt/internal/vcr"
+)
+
+// cassetteDir holds the recorded API traffic replayed when DIRT_VCR_MODE=replay.
+var cassetteDir = filepath.Join("testdata", "cassettes")
+
+// replayEndpoint is the endpoint configured while replaying; it is never contacted.
+const replayEndpoint = "http://dirt.invalid/v1"
+
+// testAccEnv is the environment of a single acceptance test.
+type testAccEnv struct {
+	// Client talks to the same API as the provider under test, for out-of-band changes.
+	Client *client.Client
+
+	// ProtoV6ProviderFactories serve a provider configured for the test's API.
+	ProtoV6ProviderFactories map[string]func() (tfprotov6.ProviderServer, error)
+
+	// replay is set when the API traffic is served from the test's cassette.
+	replay bool
+}
+
+// Test runs testCase. Replayed test cases run as unit tests, without TF_ACC, since they
+// never contact a server.
+func (env *testAccEnv) Test(t *testing.T, testCase resource.TestCase) {
+	t.Helper()
+
+	if env.replay {
+		resource.UnitTest(t, testCase)
+		return
+	}
+	resource.Test(t, testCase)
+}
+
+// newTestAccEnv prepares an acceptance test. By default it starts an in-process
+// DirtCloud server and switches it to deterministic mode with dirttest.Deterministic, so
+// every run sees the same IDs and timestamps; DIRT_ENDPOINT selects an external server
+// instead. With DIRT_VCR_MODE=record the test's API traffic is written to its cassette;
+// with DIRT_VCR_MODE=replay it is served from the cassette, no server is used and TF_ACC
+// is not required. Options customize the in-process server; tests passing any are
+// skipped against an external one.
+func newTestAccEnv(t *testing.T, options ...testAccEnvOption) *testAccEnv {
+	t.Helper()
+
+	mode, _ := vcr.ModeFromEnv()
+	if mode != vcr.ModeReplay && os.Getenv(resource.EnvTfAcc) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set, or %s=%s", resource.EnvTfAcc, vcr.EnvMode, vcr.ModeReplay)
+	}
+
+	httpClient := vcr.ForTest(t, cassetteDir)
+
+	var config testAccServerConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	endpoint := os.Getenv("DIRT_ENDPOINT")
+	switch {
+	case mode == vcr.ModeReplay:
+		endpoint = replayEndpoint
+	case endpoint == "":
+		var handler http.Handler = server.New(config.options)
+		for _, middleware := range config.middleware {
+			handler = middleware(handler)
+		}
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+		endpoint = srv.URL + "/v1"
+
+		// Enabled out of band, so the cassettes do not depend on how the server was set up
+		setup := client.NewClient(endpoint)
+		setup.Token = config.adminToken
+		dirttest.Deterministic(t, setup, 0)
+	case len(options) > 0:
+		t.Skip("Test needs the in-process server, skipped with DIRT_ENDPOINT set")
+	}
+
+	c := client.NewClient(endpoint)
+	if config.adminToken != "" {
+		c.Token = config.adminToken
+	}
+	if httpClient != nil {
+		c.HTTPClient = httpClient
+	}
+
+	return &testAccEnv{
+		Client: c,
+		replay: mode == vcr.ModeReplay,
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"dirt": providerserver.NewProtocol6WithError(&DirtProvider{
+				version:    "test",
+				endpoint:   endpoint,
+				httpClient: httpClient,
+			}),
+		},
+	}
+}
+
+// testAccServerConfig is the configuration of the in-process server of an acceptance test.
+type testAccServerConfig struct {
+	options    server.Options
+	middleware []func(http.Handler) http.Handler
+
+	// adminToken authenticates the test's own requests to a server requiring tokens.
+	adminToken string
+}
+
+// testAccEnvOption customizes the in-process server of an acceptance test.
+type testAccEnvOption func(*testAccServerConfig)
+
+// withServerOptions lets configure change the options of the in-process server.
+func withServerOptions(configure func(*server.Options)) testAccEnvOption {
+	return func(c *testAccServerConfig) {
+		configure(&c.options)
+	}
+}
+
+// withMiddleware wraps the in-process server in middleware, e.g. to emulate a server
+// that behaves differently from it. Middleware added later wraps that added earlier.
+func withMiddleware(middleware func(http.Handler) http.Handler) testAccEnvOption {
+	return func(c *testAccServerConfig) {
+		c.middleware = append(c.middleware, middleware)
+	}
+}
+
+// withTokens makes the in-process server require tokens. The test's own requests, e.g.
+// through testAccEnv.Client, use the first admin token not scoped to projects.
+func withTokens(tokens ...server.Token) testAccEnvOption {
+	return func(c *testAccServerConfig) {
+		c.options.Tokens = &server.TokenConfig{Tokens: tokens}
+		for _, token := range tokens {
+			if token.Role == server.RoleAdmin && len(token.Projects) == 0 {
+				c.adminToken = token.Token
+				break
+			}
+		}
+	}
+}
+
+// inProcessOnly skips the test against an external server, e.g. because it asserts the
+// exact IDs and timestamps of the in-process server's deterministic mode.
+func inProcessOnly() testAccEnvOption {
+	return func(*testAccServerConfig) {}
+}
+
+// withoutNameFilter makes the in-process server ignore the name filter of list requests,
+// like a server matching names by prefix or substring would return more than asked for.
+func withoutNameFilter() testAccEnvOption {
+	return withMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Query().Has("name") {
+				query := r.URL.Query()
+				query.Del("name")
+				r.URL.RawQuery = query.Encode()
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// testAccPlanWarnings records the warnings the provider returns when planning resource
+// changes, which the testing framework does not expose.
+type testAccPlanWarnings struct {
+	mu       sync.Mutex
+	warnings []*tfprotov6.Diagnostic
+}
+
+// Factories serves the providers of env through w.
+func (w *testAccPlanWarnings) Factories(env *testAccEnv) map[string]func() (tfprotov6.ProviderServer, error) {
+	factories := map[string]func() (tfprotov6.ProviderServer, error){}
+	for name, factory := range env.ProtoV6ProviderFactories {
+		factories[name] = func() (tfprotov6.ProviderServer, error) {
+			server, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			return &planWarningRecorder{ProviderServer: server, warnings: w}, nil
+		}
+	}
+	return factories
+}
+
+// Expect fails unless a warning with the given summary and a detail matching detail was
+// recorded since the last check. The recorded warnings are cleared.
+func (w *testAccPlanWarnings) Expect(summary string, detail *regexp.Regexp) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		warnings := w.warnings
+		w.warnings = nil
+		for _, warning := range warnings {
+			if warning.Summary == summary && detail.MatchString(warning.Detail) {
+				return nil
+			}
+		}
+		return fmt.Errorf("no %q warning matching %q among the %d planned", summary, detail, len(warnings))
+	}
+}
+
+// ExpectNone fails if any warning was recorded since the last check. The recorded
+// warnings are cleared.
+func (w *testAccPlanWarnings) ExpectNone() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		warnings := w.warnings
+		w.warnings = nil
+		if len(warnings) > 0 {
+			return fmt.Errorf("planned warning %q: %s", warnings[0].Summary, warnings[0].Detail)
+		}
+		return nil
+	}
+}
+
+// planWarningRecorder is a provider server recording the warnings of its plans.
+type planWarningRecorder struct {
+	tfprotov6.ProviderServer
+	warnings *testAccPlanWarnings
+}
+
+// PlanResourceChange implements tfprotov6.ProviderServer.
+func (p *planWarningRecorder) PlanResourceChange(ctx context.Context, req *tfprotov6.PlanResourceChangeRequest) (*tfprotov6.PlanResourceChangeResponse, error) {
+	resp, err := p.ProviderServer.PlanResourceChange(ctx, req)
+	if resp != nil {
+		p.warnings.mu.Lock()
+		for _, d := range resp.Diagnostics {
+			if d.Severity == tfprotov6.DiagnosticSeverityWarning {
+				p.warnings.warnings = append(p.warnings.warnings, d)
+			}
+		}
+		p.warnings.mu.Unlock()
+	}
+	return resp, err
+}
+
+// testAccCaptureID stores the ID of the resource at address in id, so later steps can
+// change the resource out of band.
+func testAccCaptureID(address string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", address)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckIDChanged fails if the resource at address still has the ID in previous,
+// i.e. if it was not replaced.
+func testAccCheckIDChanged(address string, previous *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", address)
+		}
+		if rs.Primary.ID == *previous {
+			return fmt.Errorf("resource %s was not replaced, ID is still %s", address, *previous)
+		}
+		return nil
+	}
+}
+
+func TestAccEnv_withSnapshot(t *testing.T) {
+	env := newTestAccEnv(t, inProcessOnly())
+	dirttest.WithSnapshot(t, env.Client, filepath.Join("testdata", "snapshots", "golden.json"))
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dirt_project" "golden" {
+  name = "golden-project"
+}
+
+data "dirt_instance" "golden" {
+  project_id = data.dirt_project.golden.id
+  name       = "golden-web"
+}
+
+data "dirt_metadata" "golden" {
+  path = "golden/region"
+}
+
+resource "dirt_project" "test" {
+  name = "tf-acc-snapshot"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dirt_project.golden", "id", "proj-0101"),
+					resource.TestCheckResourceAttr("data.dirt_instance.golden", "id", "inst-0101"),
+					resource.TestCheckResourceAttr("data.dirt_instance.golden", "image", "nginx:1.27"),
+					resource.TestCheckResourceAttr("data.dirt_metadata.golden", "value", "eu-west-1"),
+					resource.TestCheckResourceAttr("dirt_project.test", "id", "proj-0001"),
+					testAccCheckSnapshotProjects(t, env, "golden-project", "tf-acc-snapshot"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckSnapshotProjects saves a snapshot of the server and checks that it holds
+// exactly the named projects.
+func testAccCheckSnapshotProjects(t *testing.T, env *testAccEnv, want ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		file := filepath.Join(t.TempDir(), "snapshot.json")
+		dirttest.SaveSnapshot(t, env.Client, file)
+
+		var got []string
+		for _, p := range dirttest.LoadSnapshot(t, file).Projects {
+			got = append(got, p.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("snapshot has projects %v, want %v", got, want)
+		}
+		return nil
+	}
+}
+
+func TestAccProvider_authErrors(t *testing.T) {
+	env := newTestAccEnv(t, withTokens(
+		server.Token{Name: "admin", Token: "tf-acc-admin-token", Role: server.RoleAdmin},
+		server.Token{Name: "auditor", Token: "tf-acc-viewer-token", Role: server.RoleViewer},
+		server.Token{Name: "payments-ci", Token: "tf-acc-payments-token", Role: server.RoleEditor, Projects: []string{"payments"}},
+	))
+
+	project := `
+resource "dirt_project" "test" {
+  name = "tf-acc-auth"
+}
+`
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderTokenConfig("tf-acc-admin-token") + project,
+			},
+			// An unknown token points at the token settings
+			{
+				Config: testAccProviderTokenConfig("tf-acc-wrong-token") + project + `
+data "dirt_project" "test" {
+  id = dirt_project.test.id
+}
+`,
+				ExpectError: regexp.MustCompile(`Authentication Failed(?s:.*)DIRT_TOKEN`),
+			},
+			// A viewer may read but not create projects
+			{
+				Config: testAccProviderTokenConfig("tf-acc-viewer-token") + project + `
+resource "dirt_project" "other" {
+  name = "tf-acc-auth-other"
+}
+`,
+				ExpectError: regexp.MustCompile(`Permission Denied(?s:.*)token\s+is\s+not\s+allowed\s+to\s+create\s+project(?s:.*)token\s+"auditor"\s+\(role\s+viewer\)`),
+			},
+			// A token scoped to other projects may not read this one
+			{
+				Config: testAccProviderTokenConfig("tf-acc-payments-token") + `
+data "dirt_project" "test" {
+  id = "proj-0001"
+}
+`,
+				ExpectError: regexp.MustCompile(`Permission Denied(?s:.*)not\s+allowed\s+to\s+read\s+project\s+"proj-0001"(?s:.*)projects\s+payments`),
+			},
+			// Clean up with the admin token
+			{
+				Config: testAccProviderTokenConfig("tf-acc-admin-token") + project,
+			},
+		},
+	})
+}
+
+// testAccProviderTokenConfig configures the provider to authenticate with token.
+func testAccProviderTokenConfig(token string) string {
+	return fmt.Sprintf(`
+provider "dirt" {
+  token = %q
+}
+`, token)
+}
+
+// TestAccDataSource_exactName looks resources up by name on a server that ignores the
+// name filter, so every lookup also receives a resource whose name only starts with it.
+func TestAccDataSource_exactName(t *testing.T) {
+	env := newTestAccEnv(t, withoutNameFilter())
+
+	env.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: env.ProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dirt_project" "test" {
+  name = "tf-acc-exact"
+}
+
+resource "dirt_project" "longer" {
+  name = "tf-acc-exact-longer"
+}
+
+resource "dirt_instance" "test" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-exact"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+resource "dirt_instance" "longer" {
+  project_id = dirt_project.test.id
+  name       = "tf-acc-exact-longer"
+  cpu        = 1
+  memory_mb  = 512
+  image      = "alpine:3.20"
+}
+
+resource "dirt_bucket" "test" {
+  name = "tf-acc-exact"
+}
+
+resource "dirt_bucket" "longer" {
+  name = "tf-acc-exact-longer"
+}
+
+data "dirt_project" "test" {
+  name = dirt_project.test.name
+
+  depends_on = [dirt_project.longer]
+}
+
+data "dirt_instance" "test" {
+  project_id = dirt_project.test.id
+  name       = dirt_instance.test.name
+
+  depends_on = [dirt_instance.longer]
+}
+
+data "dirt_bucket" "test" {
+  name = dirt_bucket.test.name
+
+  depends_on = [dirt_bucket.longer]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.dirt_project.test", "id", "dirt_project.test", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_instance.test", "id", "dirt_instance.test", "id"),
+					resource.TestCheckResourceAttrPair("data.dirt_bucket.test", "id", "dirt_bucket.test", "id"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// sweepPrefix starts the name, or metadata path, of everything acceptance tests create.
+// Sweepers delete whatever carries it.
+const sweepPrefix = "tf-acc-"
+
+// TestMain runs the sweepers when -sweep is given, e.g.
+// go test ./internal/provider -v -sweep=local, and the tests otherwise.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("dirt_object", &resource.Sweeper{
+		Name: "dirt_object",
+		F:    sweepObjects,
+	})
+	resource.AddTestSweepers("dirt_bucket", &resource.Sweeper{
+		Name:         "dirt_bucket",
+		Dependencies: []string{"dirt_object"},
+		F:            sweepBuckets,
+	})
+	resource.AddTestSweepers("dirt_instance", &resource.Sweeper{
+		Name: "dirt_instance",
+		F:    sweepInstances,
+	})
+	resource.AddTestSweepers("dirt_project", &resource.Sweeper{
+		Name:         "dirt_project",
+		Dependencies: []string{"dirt_instance"},
+		F:            sweepProjects,
+	})
+	resource.AddTestSweepers("dirt_metadata", &resource.Sweeper{
+		Name: "dirt_metadata",
+		F:    sweepMetadata,
+	})
+}
+
+// sweepClient returns a client for the server of region. The only region is "local",
+// the server at DIRT_ENDPOINT (or the default endpoint) authenticated with DIRT_TOKEN.
+func sweepClient(region string) (*client.Client, error) {
+	if region != "local" {
+		return nil, fmt.Errorf("unknown sweeper region %q, expected local", region)
+	}
+
+	c := client.NewClient(os.Getenv("DIRT_ENDPOINT"))
+	c.UserAgent = "terraform-provider-dirt/sweeper"
+	return c, nil
+}
+
+// sweepDelete deletes a leftover resource, ignoring ones that are already gone.
+func sweepDelete(resourceType, id, name string, del func() error) error {
+	log.Printf("[INFO] Sweeping %s %s (%s)", resourceType, id, name)
+
+	if err := del(); err != nil && !isNotFound(err) {
+		return fmt.Errorf("deleting %s %s: %w", resourceType, id, err)
+	}
+	return nil
+}
+
+// sweepObjects deletes objects in test buckets as well as test objects in other buckets.
+func sweepObjects(region string) error {
+	c, err := sweepClient(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	buckets, err := c.ListBuckets(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing buckets: %w", err)
+	}
+
+	var errs []error
+	for _, b := range buckets {
+		objects, err := c.ListObjects(ctx, b.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing objects of bucket %s: %w", b.ID, err))
+			continue
+		}
+
+		for _, o := range objects {
+			if !strings.HasPrefix(b.Name, sweepPrefix) && !strings.HasPrefix(o.Path, sweepPrefix) {
+				continue
+			}
+			errs = append(errs, sweepDelete("object", o.ID, b.Name+"/"+o.Path, func() error {
+				return c.DeleteObject(ctx, b.ID, o.ID)
+			}))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepBuckets deletes test buckets.
+func sweepBuckets(region string) error {
+	c, err := sweepClient(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	buckets, err := c.ListBuckets(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing buckets: %w", err)
+	}
+
+	var errs []error
+	for _, b := range buckets {
+		if !strings.HasPrefix(b.Name, sweepPrefix) {
+			continue
+		}
+		errs = append(errs, sweepDelete("bucket", b.ID, b.Name, func() error {
+			return c.DeleteBucket(ctx, b.ID)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepInstances deletes instances in test projects as well as test instances in other
+// projects.
+func sweepInstances(region string) error {
+	c, err := sweepClient(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	projects, err := c.ListProjects(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing projects: %w", err)
+	}
+	testProjects := map[string]bool{}
+	for _, p := range projects {
+		if strings.HasPrefix(p.Name, sweepPrefix) {
+			testProjects[p.ID] = true
+		}
+	}
+
+	instances, err := c.ListInstances(ctx, "", "", "")
+	if err != nil {
+		return fmt.Errorf("listing instances: %w", err)
+	}
+
+	var errs []error
+	for _, i := range instances {
+		if !testProjects[i.ProjectID] && !strings.HasPrefix(i.Name, sweepPrefix) {
+			continue
+		}
+		errs = append(errs, sweepDelete("instance", i.ID, i.Name, func() error {
+			return c.DeleteInstance(ctx, i.ID)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepProjects deletes test projects.
+func sweepProjects(region string) error {
+	c, err := sweepClient(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	projects, err := c.ListProjects(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing projects: %w", err)
+	}
+
+	var errs []error
+	for _, p := range projects {
+		if !strings.HasPrefix(p.Name, sweepPrefix) {
+			continue
+		}
+		errs = append(errs, sweepDelete("project", p.ID, p.Name, func() error {
+			return c.DeleteProject(ctx, p.ID)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweepMetadata deletes metadata whose path starts with the test prefix.
+func sweepMetadata(region string) error {
+	c, err := sweepClient(region)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	metadata, err := c.ListMetadata(ctx, sweepPrefix)
+	if err != nil {
+		return fmt.Errorf("listing metadata: %w", err)
+	}
+
+	var errs []error
+	for _, m := range metadata {
+		errs = append(errs, sweepDelete("metadata", m.ID, m.Path, func() error {
+			return c.DeleteMetadata(ctx, m.ID)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
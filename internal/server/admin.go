@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Snapshot returns a portable copy of the full server state.
+func (s *Server) Snapshot() client.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.snapshot()
+}
+
+// Restore replaces the full server state with snapshot. The snapshot is validated first;
+// on error the current state is left untouched.
+func (s *Server) Restore(snapshot client.Snapshot) error {
+	state, err := stateFromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commit(s.restoreRecords(state)...)
+}
+
+// Reset deletes all server state. In deterministic mode the ID sequences and the
+// clock start over too, so a reset server reproduces the same IDs and timestamps.
+func (s *Server) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.commit(s.resetRecords()...); err != nil {
+		return err
+	}
+	s.opts.IDs.Reset()
+	s.opts.Clock.Reset()
+	return nil
+}
+
+// stateFromSnapshot builds a State from a snapshot, checking IDs are unique and that
+// instances and objects reference existing projects and buckets.
+func stateFromSnapshot(snapshot client.Snapshot) (*State, error) {
+	if snapshot.Version != client.SnapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, client.SnapshotFormatVersion)
+	}
+
+	state := newState()
+
+	for _, p := range snapshot.Projects {
+		if p.ID == "" {
+			return nil, fmt.Errorf("project %q has no id", p.Name)
+		}
+		if _, ok := state.Projects[p.ID]; ok {
+			return nil, fmt.Errorf("duplicate project id %q", p.ID)
+		}
+		state.Projects[p.ID] = &p
+	}
+
+	for _, i := range snapshot.Instances {
+		if i.ID == "" {
+			return nil, fmt.Errorf("instance %q has no id", i.Name)
+		}
+		if _, ok := state.Instances[i.ID]; ok {
+			return nil, fmt.Errorf("duplicate instance id %q", i.ID)
+		}
+		if _, ok := state.Projects[i.ProjectID]; !ok {
+			return nil, fmt.Errorf("instance %q references unknown project %q", i.ID, i.ProjectID)
+		}
+		state.Instances[i.ID] = &i
+	}
+
+	for _, m := range snapshot.Metadata {
+		if m.ID == "" {
+			return nil, fmt.Errorf("metadata %q has no id", m.Path)
+		}
+		if _, ok := state.Metadata[m.ID]; ok {
+			return nil, fmt.Errorf("duplicate metadata id %q", m.ID)
+		}
+		m.ValueSHA256 = valueSHA256(m.Value)
+		m.Version = max(m.Version, 1)
+		state.Metadata[m.ID] = &m
+	}
+
+	for _, v := range snapshot.MetadataVersions {
+		if _, ok := state.Metadata[v.MetadataID]; !ok {
+			return nil, fmt.Errorf("version %d references unknown metadata %q", v.Version, v.MetadataID)
+		}
+		key := metadataVersionKey(v.MetadataID, v.Version)
+		if _, ok := state.MetadataVersions[key]; ok {
+			return nil, fmt.Errorf("duplicate version %d of metadata %q", v.Version, v.MetadataID)
+		}
+		v.ValueSHA256 = valueSHA256(v.Value)
+		state.MetadataVersions[key] = &v
+	}
+	// Snapshots taken before versioning start each history at the current state
+	for _, m := range state.Metadata {
+		key := metadataVersionKey(m.ID, m.Version)
+		if _, ok := state.MetadataVersions[key]; !ok {
+			v := newMetadataVersion(*m)
+			state.MetadataVersions[key] = &v
+		}
+	}
+
+	for _, b := range snapshot.Buckets {
+		if b.ID == "" {
+			return nil, fmt.Errorf("bucket %q has no id", b.Name)
+		}
+		if _, ok := state.Buckets[b.ID]; ok {
+			return nil, fmt.Errorf("duplicate bucket id %q", b.ID)
+		}
+		state.Buckets[b.ID] = &b
+	}
+
+	for _, o := range snapshot.Objects {
+		if o.ID == "" {
+			return nil, fmt.Errorf("object %q has no id", o.Path)
+		}
+		if _, ok := state.Objects[o.ID]; ok {
+			return nil, fmt.Errorf("duplicate object id %q", o.ID)
+		}
+		if _, ok := state.Buckets[o.BucketID]; !ok {
+			return nil, fmt.Errorf("object %q references unknown bucket %q", o.ID, o.BucketID)
+		}
+		state.Objects[o.ID] = &o
+	}
+
+	state.Events = append(state.Events, snapshot.Events...)
+
+	for i, e := range snapshot.MetadataEvents {
+		if i > 0 && e.Revision <= snapshot.MetadataEvents[i-1].Revision {
+			return nil, fmt.Errorf("metadata event revision %d is not after %d", e.Revision, snapshot.MetadataEvents[i-1].Revision)
+		}
+	}
+	state.MetadataEvents = append(state.MetadataEvents, snapshot.MetadataEvents...)
+
+	return state, nil
+}
+
+func (s *Server) snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Snapshot())
+}
+
+func (s *Server) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	var snapshot client.Snapshot
+	if !decode(w, r, &snapshot) {
+		return
+	}
+
+	state, err := stateFromSnapshot(snapshot)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_snapshot", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.save(w, s.restoreRecords(state)...) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) resetHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.Reset(); err != nil {
+		writeError(w, http.StatusInternalServerError, "storage_error", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
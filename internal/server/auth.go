@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Role is the level of access a token grants.
+type Role string
+
+// Roles, from least to most privileged. Viewers can read everything in scope; editors
+// can also change instances, metadata, buckets, objects and project names; admins can
+// also create and delete projects and, when not scoped to projects, use the admin API.
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// rank orders roles by privilege.
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Token is an API token and the access it grants.
+type Token struct {
+	// Name identifies the token holder in error messages and audit events.
+	Name string `json:"name"`
+	// Token is the bearer token value.
+	Token string `json:"token"`
+	// Role is the access level granted by the token.
+	Role Role `json:"role"`
+	// Projects optionally restricts the token to projects with these names or IDs.
+	// Project scoping applies to projects, instances and their events; metadata and
+	// buckets are account-wide and governed by the role alone.
+	Projects []string `json:"projects,omitempty"`
+}
+
+// TokenConfig lists the tokens accepted by a server.
+type TokenConfig struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadTokensFile reads and validates a token configuration from a JSON file.
+func LoadTokensFile(path string) (*TokenConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tokens: %w", err)
+	}
+
+	var config TokenConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decoding tokens %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("tokens %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks that every token has a unique name and value and a known role.
+func (c *TokenConfig) Validate() error {
+	names := map[string]bool{}
+	values := map[string]bool{}
+
+	for i, t := range c.Tokens {
+		if t.Name == "" {
+			return fmt.Errorf("token %d has no name", i)
+		}
+		if t.Token == "" {
+			return fmt.Errorf("token %q has no token value", t.Name)
+		}
+		if t.Role.rank() == 0 {
+			return fmt.Errorf("token %q has invalid role %q, expected viewer, editor or admin", t.Name, t.Role)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate token name %q", t.Name)
+		}
+		if values[t.Token] {
+			return fmt.Errorf("token %q reuses the value of another token", t.Name)
+		}
+		names[t.Name] = true
+		values[t.Token] = true
+	}
+
+	return nil
+}
+
+// lookup returns the token with the given value, or nil.
+func (c *TokenConfig) lookup(value string) *Token {
+	for i := range c.Tokens {
+		if subtle.ConstantTimeCompare([]byte(c.Tokens[i].Token), []byte(value)) == 1 {
+			return &c.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// scoped reports whether the token is restricted to a set of projects.
+func (t *Token) scoped() bool {
+	return len(t.Projects) > 0
+}
+
+// allowsProject reports whether the token may access project.
+func (t *Token) allowsProject(project *client.Project) bool {
+	if !t.scoped() {
+		return true
+	}
+	for _, p := range t.Projects {
+		if p == project.ID || p == project.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// serverResource is the resource name of admin API routes, which manage the whole
+// server and are therefore never available to project-scoped tokens.
+const serverResource = "server"
+
+type principalKey struct{}
+
+// principal returns the token that authenticated the request, or nil when the server
+// does not require authentication.
+func principal(r *http.Request) *Token {
+	t, _ := r.Context().Value(principalKey{}).(*Token)
+	return t
+}
+
+// handle registers a route that requires at least role. action and resource describe
+// the operation in authorization errors, e.g. "update" and "instance".
+func (s *Server) handle(pattern string, role Role, action, resource string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Tokens == nil {
+			h(w, r)
+			return
+		}
+
+		value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || value == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dirtcloud"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+			return
+		}
+
+		token := s.opts.Tokens.lookup(value)
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dirtcloud", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", "invalid token")
+			return
+		}
+
+		if token.Role.rank() < role.rank() || (resource == serverResource && token.scoped()) {
+			forbid(w, token, action, describe(resource, r.PathValue("id")))
+			return
+		}
+
+		h(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, token)))
+	})
+}
+
+// canAccessProject reports whether the caller may access project, writing a 403 naming
+// action if not. It must be called with s.mu held.
+func (s *Server) canAccessProject(w http.ResponseWriter, r *http.Request, action string, project *client.Project) bool {
+	token := principal(r)
+	if token == nil || token.allowsProject(project) {
+		return true
+	}
+
+	forbid(w, token, action, describe("project", project.ID))
+	return false
+}
+
+// canAccessInstance reports whether the caller may access instance through its project,
+// writing a 403 naming action if not. It must be called with s.mu held.
+func (s *Server) canAccessInstance(w http.ResponseWriter, r *http.Request, action string, instance *client.Instance) bool {
+	token := principal(r)
+	if token == nil || s.visibleProject(r, instance.ProjectID) {
+		return true
+	}
+
+	forbid(w, token, action, describe("instance", instance.ID))
+	return false
+}
+
+// visibleProject reports whether the caller may see the project with the given ID. It
+// must be called with s.mu held.
+func (s *Server) visibleProject(r *http.Request, projectID string) bool {
+	token := principal(r)
+	if token == nil || !token.scoped() {
+		return true
+	}
+
+	project, ok := s.state.Projects[projectID]
+	return ok && token.allowsProject(project)
+}
+
+// visibleEvent reports whether the caller may see an audit event. Scoped tokens only see
+// events of projects and instances in their projects. It must be called with s.mu held.
+func (s *Server) visibleEvent(r *http.Request, e client.Event) bool {
+	token := principal(r)
+	if token == nil || !token.scoped() {
+		return true
+	}
+
+	switch e.ResourceType {
+	case "project":
+		for _, f := range []map[string]interface{}{e.After, e.Before} {
+			if name, ok := f["name"].(string); ok && token.allowsProject(&client.Project{ID: e.ResourceID, Name: name}) {
+				return true
+			}
+		}
+	case "instance":
+		for _, f := range []map[string]interface{}{e.After, e.Before} {
+			if id, ok := f["project_id"].(string); ok && s.visibleProject(r, id) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// describe names a resource for authorization errors.
+func describe(resource, id string) string {
+	if id == "" {
+		return resource
+	}
+	return resource + " " + id
+}
+
+// forbid writes a 403 response naming the token, the action and the resource.
+func forbid(w http.ResponseWriter, token *Token, action, resource string) {
+	scope := ""
+	if token.scoped() {
+		scope = fmt.Sprintf(", projects %s", strings.Join(token.Projects, ", "))
+	}
+
+	writeError(w, http.StatusForbidden, "forbidden",
+		fmt.Sprintf("token %q (role %s%s) is not allowed to %s %s", token.Name, token.Role, scope, action, resource))
+}
@@ -0,0 +1,318 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// testTokens are the tokens of the server started by newAuthTestServer.
+var testTokens = &TokenConfig{Tokens: []Token{
+	{Name: "admin", Token: "admin-token", Role: RoleAdmin},
+	{Name: "editor", Token: "editor-token", Role: RoleEditor},
+	{Name: "viewer", Token: "viewer-token", Role: RoleViewer},
+	{Name: "payments-editor", Token: "payments-editor-token", Role: RoleEditor, Projects: []string{"payments"}},
+	{Name: "payments-admin", Token: "payments-admin-token", Role: RoleAdmin, Projects: []string{"payments"}},
+}}
+
+// authTestFixture holds the resources newAuthTestServer creates: a project the scoped
+// tokens may access and one they may not, each with an instance.
+type authTestFixture struct {
+	payments, billing                 *client.Project
+	paymentsInstance, billingInstance *client.Instance
+	metadata                          *client.Metadata
+}
+
+// newAuthTestServer starts a server requiring testTokens and creates the fixture as admin.
+// It returns the server URL.
+func newAuthTestServer(t *testing.T) (string, *authTestFixture) {
+	t.Helper()
+
+	srv := httptest.NewServer(New(Options{Tokens: testTokens}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	admin := newTokenClient(srv.URL, "admin-token")
+	f := &authTestFixture{}
+	var err error
+
+	if f.payments, err = admin.CreateProject(ctx, client.CreateProjectRequest{Name: "payments"}); err != nil {
+		t.Fatalf("CreateProject: %s", err)
+	}
+	if f.billing, err = admin.CreateProject(ctx, client.CreateProjectRequest{Name: "billing"}); err != nil {
+		t.Fatalf("CreateProject: %s", err)
+	}
+	if f.paymentsInstance, err = admin.CreateInstance(ctx, client.CreateInstanceRequest{ProjectID: f.payments.ID, Name: "api", CPU: 1, MemoryMB: 512, Image: "alpine:3.20"}); err != nil {
+		t.Fatalf("CreateInstance: %s", err)
+	}
+	if f.billingInstance, err = admin.CreateInstance(ctx, client.CreateInstanceRequest{ProjectID: f.billing.ID, Name: "api", CPU: 1, MemoryMB: 512, Image: "alpine:3.20"}); err != nil {
+		t.Fatalf("CreateInstance: %s", err)
+	}
+	if f.metadata, err = admin.CreateMetadata(ctx, client.CreateMetadataRequest{Path: "app/region", Value: "eu-west-1"}); err != nil {
+		t.Fatalf("CreateMetadata: %s", err)
+	}
+
+	return srv.URL, f
+}
+
+// newTokenClient returns a client for the server at url authenticating with token.
+func newTokenClient(url, token string) *client.Client {
+	c := client.NewClient(url + "/v1")
+	c.Token = token
+	return c
+}
+
+// wantStatus fails the test unless err is an *client.APIError with the given status, or
+// nil if status is 0.
+func wantStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	if status == 0 {
+		if err != nil {
+			t.Errorf("got error %s, want success", err)
+		}
+		return
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+		t.Errorf("got error %v, want HTTP %d", err, status)
+	}
+}
+
+func TestAuth_roles(t *testing.T) {
+	url, f := newAuthTestServer(t)
+
+	name := "renamed"
+	value := "us-east-1"
+	operations := map[string]func(ctx context.Context, c *client.Client) error{
+		"read project": func(ctx context.Context, c *client.Client) error {
+			_, err := c.GetProject(ctx, f.payments.ID)
+			return err
+		},
+		"read metadata": func(ctx context.Context, c *client.Client) error {
+			_, err := c.GetMetadata(ctx, f.metadata.ID)
+			return err
+		},
+		"update instance": func(ctx context.Context, c *client.Client) error {
+			_, err := c.UpdateInstance(ctx, f.paymentsInstance.ID, client.UpdateInstanceRequest{Name: &name})
+			return err
+		},
+		"update metadata": func(ctx context.Context, c *client.Client) error {
+			_, err := c.UpdateMetadata(ctx, f.metadata.ID, client.UpdateMetadataRequest{Value: &value})
+			return err
+		},
+		"create project": func(ctx context.Context, c *client.Client) error {
+			_, err := c.CreateProject(ctx, client.CreateProjectRequest{Name: "new-project"})
+			return err
+		},
+		"snapshot": func(ctx context.Context, c *client.Client) error {
+			_, err := c.Snapshot(ctx)
+			return err
+		},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  map[string]int
+	}{
+		{
+			name:  "viewer",
+			token: "viewer-token",
+			want: map[string]int{
+				"update instance": http.StatusForbidden,
+				"update metadata": http.StatusForbidden,
+				"create project":  http.StatusForbidden,
+				"snapshot":        http.StatusForbidden,
+			},
+		},
+		{
+			name:  "editor",
+			token: "editor-token",
+			want: map[string]int{
+				"create project": http.StatusForbidden,
+				"snapshot":       http.StatusForbidden,
+			},
+		},
+		{
+			name:  "admin",
+			token: "admin-token",
+			want:  map[string]int{},
+		},
+		{
+			// Scoped tokens never get the admin API whatever their role, and only create
+			// the projects they are scoped to
+			name:  "scoped admin",
+			token: "payments-admin-token",
+			want: map[string]int{
+				"create project": http.StatusForbidden,
+				"snapshot":       http.StatusForbidden,
+			},
+		},
+		{
+			name:  "no token",
+			token: "",
+			want: map[string]int{
+				"read project":    http.StatusUnauthorized,
+				"read metadata":   http.StatusUnauthorized,
+				"update instance": http.StatusUnauthorized,
+				"update metadata": http.StatusUnauthorized,
+				"create project":  http.StatusUnauthorized,
+				"snapshot":        http.StatusUnauthorized,
+			},
+		},
+		{
+			name:  "unknown token",
+			token: "not-a-token",
+			want: map[string]int{
+				"read project":    http.StatusUnauthorized,
+				"read metadata":   http.StatusUnauthorized,
+				"update instance": http.StatusUnauthorized,
+				"update metadata": http.StatusUnauthorized,
+				"create project":  http.StatusUnauthorized,
+				"snapshot":        http.StatusUnauthorized,
+			},
+		},
+	} {
+		for operation, call := range operations {
+			t.Run(tc.name+"/"+operation, func(t *testing.T) {
+				err := call(context.Background(), newTokenClient(url, tc.token))
+				wantStatus(t, err, tc.want[operation])
+			})
+		}
+	}
+}
+
+func TestAuth_projectScope(t *testing.T) {
+	url, f := newAuthTestServer(t)
+	ctx := context.Background()
+	c := newTokenClient(url, "payments-editor-token")
+
+	name := "renamed"
+	for _, tc := range []struct {
+		name   string
+		call   func() error
+		status int
+	}{
+		{"read own project", func() error { _, err := c.GetProject(ctx, f.payments.ID); return err }, 0},
+		{"read other project", func() error { _, err := c.GetProject(ctx, f.billing.ID); return err }, http.StatusForbidden},
+		{"read own instance", func() error { _, err := c.GetInstance(ctx, f.paymentsInstance.ID); return err }, 0},
+		{"read other instance", func() error { _, err := c.GetInstance(ctx, f.billingInstance.ID); return err }, http.StatusForbidden},
+		{"update other instance", func() error {
+			_, err := c.UpdateInstance(ctx, f.billingInstance.ID, client.UpdateInstanceRequest{Name: &name})
+			return err
+		}, http.StatusForbidden},
+		{"create instance in other project", func() error {
+			_, err := c.CreateInstance(ctx, client.CreateInstanceRequest{ProjectID: f.billing.ID, Name: "worker", CPU: 1, MemoryMB: 512, Image: "alpine:3.20"})
+			return err
+		}, http.StatusForbidden},
+		{"delete other instance", func() error { return c.DeleteInstance(ctx, f.billingInstance.ID) }, http.StatusForbidden},
+		{"read other quota", func() error { _, err := c.GetProjectQuota(ctx, f.billing.ID); return err }, http.StatusForbidden},
+		// Metadata is account-wide and governed by the role alone
+		{"read metadata", func() error { _, err := c.GetMetadata(ctx, f.metadata.ID); return err }, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wantStatus(t, tc.call(), tc.status)
+		})
+	}
+
+	// Lists leave out what the token cannot see
+	projects, err := c.ListProjects(ctx, "")
+	if err != nil {
+		t.Fatalf("ListProjects: %s", err)
+	}
+	if len(projects) != 1 || projects[0].ID != f.payments.ID {
+		t.Errorf("ListProjects = %+v, want only %s", projects, f.payments.ID)
+	}
+
+	instances, err := c.ListInstances(ctx, "", "", "")
+	if err != nil {
+		t.Fatalf("ListInstances: %s", err)
+	}
+	if len(instances) != 1 || instances[0].ID != f.paymentsInstance.ID {
+		t.Errorf("ListInstances = %+v, want only %s", instances, f.paymentsInstance.ID)
+	}
+
+	events, err := c.ListEvents(ctx, client.EventFilter{})
+	if err != nil {
+		t.Fatalf("ListEvents: %s", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.ResourceType+" "+e.ResourceID)
+	}
+	sort.Strings(got)
+	want := []string{"instance " + f.paymentsInstance.ID, "project " + f.payments.ID}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListEvents returned events of %v, want %v", got, want)
+	}
+}
+
+func TestAuth_forbiddenMessage(t *testing.T) {
+	url, f := newAuthTestServer(t)
+
+	_, err := newTokenClient(url, "payments-editor-token").GetInstance(context.Background(), f.billingInstance.ID)
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, want an *APIError", err)
+	}
+	want := `token "payments-editor" (role editor, projects payments) is not allowed to read instance ` + f.billingInstance.ID
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Code != "forbidden" || apiErr.Message != want {
+		t.Errorf("got %d %s %q, want 403 forbidden %q", apiErr.StatusCode, apiErr.Code, apiErr.Message, want)
+	}
+}
+
+func TestAuth_disabled(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}))
+	t.Cleanup(srv.Close)
+
+	// Without a token configuration every request is allowed, with or without a token
+	for _, token := range []string{"", "anything"} {
+		c := newTokenClient(srv.URL, token)
+		if _, err := c.CreateProject(context.Background(), client.CreateProjectRequest{Name: "open-" + token}); err != nil {
+			t.Errorf("CreateProject with token %q: %s", token, err)
+		}
+	}
+}
+
+func TestAuth_secretVersions(t *testing.T) {
+	url, _ := newAuthTestServer(t)
+	ctx := context.Background()
+
+	m, err := newTokenClient(url, "admin-token").CreateMetadata(ctx, client.CreateMetadataRequest{Path: "app/password", Value: "hunter2", Secret: true})
+	if err != nil {
+		t.Fatalf("CreateMetadata: %s", err)
+	}
+
+	// Viewers may read the history of secrets, but not their values
+	for token, want := range map[string]string{"viewer-token": "", "editor-token": "hunter2", "admin-token": "hunter2"} {
+		c := newTokenClient(url, token)
+
+		versions, err := c.ListMetadataVersions(ctx, m.ID)
+		if err != nil {
+			t.Fatalf("ListMetadataVersions with %s: %s", token, err)
+		}
+		if len(versions) != 1 || versions[0].Value != want || versions[0].ValueSHA256 != m.ValueSHA256 {
+			t.Errorf("ListMetadataVersions with %s = %+v, want value %q", token, versions, want)
+		}
+
+		version, err := c.GetMetadataVersion(ctx, m.ID, 1)
+		if err != nil {
+			t.Fatalf("GetMetadataVersion with %s: %s", token, err)
+		}
+		if version.Value != want {
+			t.Errorf("GetMetadataVersion with %s = %q, want %q", token, version.Value, want)
+		}
+	}
+}
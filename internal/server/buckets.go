@@ -0,0 +1,321 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// bucketNamePattern is the accepted bucket name grammar.
+var bucketNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validateBucketName returns an error message for an invalid bucket name, or "".
+func validateBucketName(name string) string {
+	if name == "" || len(name) > 255 {
+		return "name must be non-empty and at most 255 characters"
+	}
+	if !bucketNamePattern.MatchString(name) {
+		return "name may only contain letters, digits, '_' and '-'"
+	}
+	return ""
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateBucketRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if msg := validateBucketName(req.Name); msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bucketByName(req.Name) != nil {
+		writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("bucket with name %q already exists", req.Name))
+		return
+	}
+
+	now := s.now()
+	bucket := &client.Bucket{
+		ID:        s.newID("bucket"),
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !s.save(w, putRecord("bucket", bucket.ID, bucket), s.event(r, "create", "bucket", bucket.ID, nil, bucket)) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, bucket)
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets := []client.Bucket{}
+	for _, b := range sortedValues(s.state.Buckets, func(b *client.Bucket) time.Time { return b.CreatedAt }, func(b *client.Bucket) string { return b.ID }) {
+		if name != "" && b.Name != name {
+			continue
+		}
+		buckets = append(buckets, b)
+	}
+
+	writeJSON(w, http.StatusOK, buckets)
+}
+
+func (s *Server) getBucket(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "bucket not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bucket)
+}
+
+func (s *Server) updateBucket(w http.ResponseWriter, r *http.Request) {
+	var req client.UpdateBucketRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "bucket not found")
+		return
+	}
+
+	updated := *bucket
+	if req.Name != "" && req.Name != bucket.Name {
+		if msg := validateBucketName(req.Name); msg != "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", msg)
+			return
+		}
+		if s.bucketByName(req.Name) != nil {
+			writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("bucket with name %q already exists", req.Name))
+			return
+		}
+		updated.Name = req.Name
+	}
+	updated.UpdatedAt = s.now()
+	if !s.save(w, putRecord("bucket", bucket.ID, updated), s.event(r, "update", "bucket", bucket.ID, bucket, updated)) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "bucket not found")
+		return
+	}
+
+	// Deleting a bucket cascades to its objects.
+	var records []Record
+	for _, obj := range sortedValues(s.state.Objects, func(o *client.Object) time.Time { return o.CreatedAt }, func(o *client.Object) string { return o.ID }) {
+		if obj.BucketID == bucket.ID {
+			records = append(records, deleteRecord("object", obj.ID), s.event(r, "delete", "object", obj.ID, obj, nil))
+		}
+	}
+
+	records = append(records, deleteRecord("bucket", bucket.ID), s.event(r, "delete", "bucket", bucket.ID, bucket, nil))
+	if !s.save(w, records...) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bucketByName returns the bucket with the given name, or nil. It must be called with s.mu held.
+func (s *Server) bucketByName(name string) *client.Bucket {
+	for _, b := range s.state.Buckets {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// validateObject returns an error message for an invalid object path or content, or "".
+func validateObject(path, content string) string {
+	if path == "" || len(path) > 1024 {
+		return "path must be non-empty and at most 1024 characters"
+	}
+	if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+		return "content must be base64-encoded"
+	}
+	return ""
+}
+
+func (s *Server) createObject(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateObjectRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if msg := validateObject(req.Path, req.Content); msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucketID := r.PathValue("bucket_id")
+	if _, ok := s.state.Buckets[bucketID]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "bucket not found")
+		return
+	}
+
+	if s.objectByPath(bucketID, req.Path) != nil {
+		writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("object with path %q already exists in bucket", req.Path))
+		return
+	}
+
+	now := s.now()
+	obj := &client.Object{
+		ID:        s.newID("object"),
+		BucketID:  bucketID,
+		Path:      req.Path,
+		Content:   req.Content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !s.save(w, putRecord("object", obj.ID, obj), s.event(r, "create", "object", obj.ID, nil, obj)) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, obj)
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucketID := r.PathValue("bucket_id")
+	if _, ok := s.state.Buckets[bucketID]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "bucket not found")
+		return
+	}
+
+	objects := []client.Object{}
+	for _, o := range sortedValues(s.state.Objects, func(o *client.Object) time.Time { return o.CreatedAt }, func(o *client.Object) string { return o.ID }) {
+		if o.BucketID == bucketID {
+			objects = append(objects, o)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, objects)
+}
+
+// lookupObject returns the object addressed by the request path, writing a 404 if it is missing.
+// It must be called with s.mu held.
+func (s *Server) lookupObject(w http.ResponseWriter, r *http.Request) *client.Object {
+	obj, ok := s.state.Objects[r.PathValue("id")]
+	if !ok || obj.BucketID != r.PathValue("bucket_id") {
+		writeError(w, http.StatusNotFound, "not_found", "object not found")
+		return nil
+	}
+	return obj
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.lookupObject(w, r)
+	if obj == nil {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func (s *Server) updateObject(w http.ResponseWriter, r *http.Request) {
+	var req client.UpdateObjectRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.lookupObject(w, r)
+	if obj == nil {
+		return
+	}
+
+	updated := *obj
+	if req.Path != nil {
+		updated.Path = *req.Path
+	}
+	if req.Content != nil {
+		updated.Content = *req.Content
+	}
+	if msg := validateObject(updated.Path, updated.Content); msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+	if updated.Path != obj.Path && s.objectByPath(obj.BucketID, updated.Path) != nil {
+		writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("object with path %q already exists in bucket", updated.Path))
+		return
+	}
+
+	updated.UpdatedAt = s.now()
+	if !s.save(w, putRecord("object", obj.ID, updated), s.event(r, "update", "object", obj.ID, obj, updated)) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.lookupObject(w, r)
+	if obj == nil {
+		return
+	}
+
+	if !s.save(w, deleteRecord("object", obj.ID), s.event(r, "delete", "object", obj.ID, obj, nil)) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// objectByPath returns the object at path within a bucket, or nil. It must be called with s.mu held.
+func (s *Server) objectByPath(bucketID, path string) *client.Object {
+	for _, o := range s.state.Objects {
+		if o.BucketID == bucketID && o.Path == path {
+			return o
+		}
+	}
+	return nil
+}
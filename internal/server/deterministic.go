@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// DefaultFakeClockStart is the time a FakeClock starts at when none is given.
+var DefaultFakeClockStart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// idPrefixes maps resource kinds to the prefix of their sequential IDs.
+var idPrefixes = map[string]string{
+	"project":  "proj",
+	"instance": "inst",
+	"metadata": "meta",
+	"bucket":   "bkt",
+	"object":   "obj",
+	"event":    "evt",
+}
+
+// IDGenerator produces IDs for new resources of the given kind.
+type IDGenerator interface {
+	NewID(kind string) string
+	// Reset restarts the sequence, if any.
+	Reset()
+}
+
+// RandomIDs generates random UUIDv4 IDs. It is the default IDGenerator.
+type RandomIDs struct{}
+
+// NewID implements IDGenerator.
+func (RandomIDs) NewID(kind string) string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Reset implements IDGenerator.
+func (RandomIDs) Reset() {}
+
+// SequentialIDs generates predictable IDs such as proj-0001, counting separately per
+// resource kind and starting after a seed.
+type SequentialIDs struct {
+	mu       sync.Mutex
+	seed     int
+	counters map[string]int
+}
+
+// NewSequentialIDs returns a SequentialIDs whose first ID of each kind is seed+1.
+func NewSequentialIDs(seed int) *SequentialIDs {
+	return &SequentialIDs{seed: seed, counters: map[string]int{}}
+}
+
+// NewID implements IDGenerator.
+func (g *SequentialIDs) NewID(kind string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	prefix, ok := idPrefixes[kind]
+	if !ok {
+		prefix = kind
+	}
+
+	g.counters[kind]++
+	return fmt.Sprintf("%s-%04d", prefix, g.seed+g.counters[kind])
+}
+
+// Reset implements IDGenerator.
+func (g *SequentialIDs) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.counters = map[string]int{}
+}
+
+// Clock tells the server what time it is.
+type Clock interface {
+	Now() time.Time
+	// Reset returns the clock to its initial time, if it is controllable.
+	Reset()
+}
+
+// SystemClock reads the wall clock. It is the default Clock.
+type SystemClock struct{}
+
+// Now implements Clock.
+func (SystemClock) Now() time.Time { return time.Now().UTC() }
+
+// Reset implements Clock.
+func (SystemClock) Reset() {}
+
+// FakeClock is a Clock that only moves when told to.
+type FakeClock struct {
+	mu    sync.Mutex
+	start time.Time
+	now   time.Time
+}
+
+// NewFakeClock returns a FakeClock stopped at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	start = start.UTC()
+	return &FakeClock{start: start, now: start}
+}
+
+// Now implements Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t.UTC()
+}
+
+// Reset implements Clock.
+func (c *FakeClock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.start
+}
+
+// Deterministic returns Options for reproducible runs: sequential IDs starting after
+// seed and a fake clock stopped at start (DefaultFakeClockStart when zero).
+func Deterministic(seed int, start time.Time) Options {
+	if start.IsZero() {
+		start = DefaultFakeClockStart
+	}
+
+	return Options{
+		IDs:   NewSequentialIDs(seed),
+		Clock: NewFakeClock(start),
+	}
+}
+
+func (s *Server) deterministicHandler(w http.ResponseWriter, r *http.Request) {
+	var req client.DeterministicModeRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	opts := Deterministic(req.Seed, req.Start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.save(w, s.resetRecords()...) {
+		return
+	}
+	s.opts.IDs = opts.IDs
+	s.opts.Clock = opts.Clock
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getClockHandler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, client.ClockResponse{Now: s.now()})
+}
+
+func (s *Server) setClockHandler(w http.ResponseWriter, r *http.Request) {
+	var req client.ClockRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clock, ok := s.opts.Clock.(*FakeClock)
+	if !ok {
+		writeError(w, http.StatusConflict, "conflict", "the server clock is not controllable; enable deterministic mode first")
+		return
+	}
+
+	var advance time.Duration
+	if req.Advance != "" {
+		d, err := time.ParseDuration(req.Advance)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("advance must be a non-negative duration, got %q", req.Advance))
+			return
+		}
+		advance = d
+	}
+
+	if req.Now != nil {
+		clock.Set(*req.Now)
+	}
+	clock.Advance(advance)
+
+	writeJSON(w, http.StatusOK, client.ClockResponse{Now: clock.Now()})
+}
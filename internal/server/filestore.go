@@ -0,0 +1,391 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	journalFileName  = "journal.log"
+
+	// DefaultCompactEvery is the number of commits after which a FileStore folds its
+	// journal into a fresh snapshot.
+	DefaultCompactEvery = 1000
+)
+
+// FileStore is a durable Store kept in a directory as a snapshot plus an append-only
+// journal. Every commit is one checksummed journal line that is fsynced before Commit
+// returns. On open, a torn or corrupt tail left by a crash is discarded, so the store
+// always recovers to the last fully committed batch. Every CompactEvery commits, and
+// on Close, the journal is folded into a new snapshot.
+type FileStore struct {
+	// CompactEvery is the number of commits between compactions; zero disables
+	// compaction except on Close.
+	CompactEvery int
+
+	mu      sync.Mutex
+	dir     string
+	journal *os.File
+	size    int64
+	state   *State
+	seq     uint64
+	pending int
+	// failed is set once a durable batch could not be applied to the replica. The
+	// journal and the replica then disagree, so every later commit and compaction
+	// fails with it instead of persisting more changes.
+	failed error
+}
+
+// fileSnapshot is the on-disk snapshot format. Seq is the last journal batch it includes.
+type fileSnapshot struct {
+	Seq   uint64          `json:"seq"`
+	State client.Snapshot `json:"state"`
+}
+
+// journalBatch is the payload of a single journal line.
+type journalBatch struct {
+	Seq     uint64   `json:"seq"`
+	Records []Record `json:"records"`
+}
+
+// OpenFileStore opens or creates a FileStore in dir, recovering its state.
+func OpenFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+
+	fs := &FileStore{
+		CompactEvery: DefaultCompactEvery,
+		dir:          dir,
+		state:        newState(),
+	}
+
+	if err := fs.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := fs.replayJournal(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// loadSnapshot reads the snapshot file, if any.
+func (fs *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(fs.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	var snap fileSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	state, err := stateFromSnapshot(snap.State)
+	if err != nil {
+		return fmt.Errorf("loading snapshot: %w", err)
+	}
+
+	fs.state = state
+	fs.seq = snap.Seq
+	return nil
+}
+
+// replayJournal applies every intact journal batch newer than the snapshot, truncates
+// any torn tail, and opens the journal for appending.
+func (fs *FileStore) replayJournal() error {
+	path := filepath.Join(fs.dir, journalFileName)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening journal: %w", err)
+	}
+
+	var valid int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A final line without newline is an interrupted write.
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return fmt.Errorf("reading journal: %w", err)
+		}
+
+		batch, ok := decodeJournalLine(line)
+		if !ok {
+			break
+		}
+
+		if batch.Seq > fs.seq {
+			for _, rec := range batch.Records {
+				if err := fs.state.apply(rec); err != nil {
+					_ = f.Close()
+					return fmt.Errorf("replaying journal batch %d: %w", batch.Seq, err)
+				}
+			}
+			fs.seq = batch.Seq
+		}
+		valid += int64(len(line))
+	}
+
+	// Drop whatever follows the last intact batch.
+	if err := f.Truncate(valid); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("truncating journal: %w", err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("seeking journal: %w", err)
+	}
+
+	fs.journal = f
+	fs.size = valid
+	return nil
+}
+
+// encodeJournalLine returns batch as "<crc32> <json>\n".
+func encodeJournalLine(batch journalBatch) ([]byte, error) {
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	line := make([]byte, 0, len(payload)+10)
+	line = fmt.Appendf(line, "%08x ", crc32.ChecksumIEEE(payload))
+	line = append(line, payload...)
+	return append(line, '\n'), nil
+}
+
+// decodeJournalLine parses a journal line, reporting false if it is corrupt.
+func decodeJournalLine(line []byte) (journalBatch, bool) {
+	var batch journalBatch
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) < 10 || line[8] != ' ' {
+		return batch, false
+	}
+
+	sum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	if err != nil {
+		return batch, false
+	}
+
+	payload := line[9:]
+	if crc32.ChecksumIEEE(payload) != uint32(sum) {
+		return batch, false
+	}
+
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return batch, false
+	}
+
+	return batch, true
+}
+
+// Load implements Store.
+func (fs *FileStore) Load() (*State, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	// Hand out a copy; the store keeps its own replica for compaction.
+	return stateFromSnapshot(fs.state.snapshot())
+}
+
+// Commit implements Store.
+func (fs *FileStore) Commit(records []Record) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.journal == nil {
+		return errors.New("store is closed")
+	}
+	if fs.failed != nil {
+		return fs.failed
+	}
+
+	batch := journalBatch{Seq: fs.seq + 1, Records: records}
+	line, err := encodeJournalLine(batch)
+	if err != nil {
+		return fmt.Errorf("encoding journal batch: %w", err)
+	}
+
+	if _, err := fs.journal.Write(line); err != nil {
+		fs.rollback()
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	if err := fs.journal.Sync(); err != nil {
+		fs.rollback()
+		return fmt.Errorf("syncing journal: %w", err)
+	}
+	fs.size += int64(len(line))
+	fs.seq = batch.Seq
+
+	for _, rec := range records {
+		if err := fs.state.apply(rec); err != nil {
+			fs.failed = fmt.Errorf("store failed: journal batch %d is persisted but could not be applied: %w", batch.Seq, err)
+			return fs.failed
+		}
+	}
+
+	// The batch is durable at this point, so a failed compaction must not fail the
+	// commit; the journal keeps growing and compaction is retried on the next commit.
+	fs.pending++
+	if fs.CompactEvery > 0 && fs.pending >= fs.CompactEvery {
+		if err := fs.compact(); err != nil {
+			log.Printf("compacting %s: %s", fs.dir, err)
+		}
+	}
+	return nil
+}
+
+// rollback discards a batch that was not fully written so the journal stays well-formed.
+// It must be called with fs.mu held.
+func (fs *FileStore) rollback() {
+	_ = fs.journal.Truncate(fs.size)
+	_, _ = fs.journal.Seek(fs.size, io.SeekStart)
+}
+
+// Compact folds the journal into a new snapshot.
+func (fs *FileStore) Compact() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.compact()
+}
+
+// compact writes a snapshot of the replica and then empties the journal. A crash
+// between the two steps is harmless: journal batches already covered by the snapshot
+// are skipped on replay because their sequence number is not newer. It must be called
+// with fs.mu held.
+func (fs *FileStore) compact() error {
+	if fs.failed != nil {
+		return fs.failed
+	}
+
+	data, err := json.Marshal(fileSnapshot{Seq: fs.seq, State: fs.state.snapshot()})
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	if err := writeFileAtomic(fs.dir, snapshotFileName, data); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	// The empty journal is opened before it replaces the old one, so that commits never
+	// go to a journal that is no longer on disk.
+	journal, err := createFileAtomic(fs.dir, journalFileName)
+	if err != nil {
+		return fmt.Errorf("resetting journal: %w", err)
+	}
+
+	_ = fs.journal.Close()
+	fs.journal = journal
+	fs.size = 0
+	fs.pending = 0
+	return nil
+}
+
+// Close implements Store. It compacts the journal before closing.
+func (fs *FileStore) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.journal == nil {
+		return nil
+	}
+
+	err := fs.compact()
+	if closeErr := fs.journal.Close(); err == nil {
+		err = closeErr
+	}
+	fs.journal = nil
+	return err
+}
+
+// writeFileAtomic replaces dir/name with data via a synced temporary file and rename.
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// createFileAtomic replaces dir/name with an empty file and returns it open for reading
+// and writing. dir/name is left untouched if it fails.
+func createFileAtomic(dir, name string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+
+	// The file has replaced dir/name at this point, so it must be returned even if the
+	// rename cannot be synced; the caller would otherwise keep using the old one.
+	_ = syncDir(dir)
+	return f, nil
+}
+
+// syncDir fsyncs a directory so that renames within it are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+
+	// Some platforms do not support syncing directories; the rename is still atomic there.
+	_ = d.Sync()
+	return nil
+}
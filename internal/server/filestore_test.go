@@ -0,0 +1,373 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// openTestStore opens a FileStore in dir that never compacts on its own, and closes it
+// when the test ends unless it crashed.
+func openTestStore(t *testing.T, dir string) *FileStore {
+	t.Helper()
+
+	fs, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore: %s", err)
+	}
+	fs.CompactEvery = 0
+	t.Cleanup(func() { _ = fs.Close() })
+	return fs
+}
+
+// crash abandons fs the way a killed process would: the journal is not compacted.
+func crash(t *testing.T, fs *FileStore) {
+	t.Helper()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.journal.Close(); err != nil {
+		t.Fatalf("closing journal: %s", err)
+	}
+	fs.journal = nil
+}
+
+// commitProject commits a batch creating the project id together with its audit event.
+func commitProject(t *testing.T, fs *FileStore, id string) {
+	t.Helper()
+
+	if err := fs.Commit(projectRecords(id)); err != nil {
+		t.Fatalf("committing project %s: %s", id, err)
+	}
+}
+
+// projectRecords returns the batch creating the project id together with its audit event.
+func projectRecords(id string) []Record {
+	return []Record{
+		putRecord("project", id, &client.Project{ID: id, Name: id}),
+		eventRecord(client.Event{ID: "event-" + id, Action: "create", ResourceType: "project", ResourceID: id}),
+	}
+}
+
+// wantProjects fails the test unless the store in dir recovers exactly the given
+// projects, each with a single audit event.
+func wantProjects(t *testing.T, dir string, want ...string) *FileStore {
+	t.Helper()
+
+	fs := openTestStore(t, dir)
+	state, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+
+	got := []string{}
+	for id := range state.Projects {
+		got = append(got, id)
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if want == nil {
+		want = []string{}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recovered projects %v, want %v", got, want)
+	}
+	if len(state.Events) != len(want) {
+		t.Errorf("recovered %d events, want %d", len(state.Events), len(want))
+	}
+	return fs
+}
+
+// appendJournal appends raw bytes to the journal in dir.
+func appendJournal(t *testing.T, dir string, data []byte) {
+	t.Helper()
+
+	f, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("opening journal: %s", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("appending to journal: %s", err)
+	}
+}
+
+func TestFileStore_reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+	commitProject(t, fs, "proj-2")
+	crash(t, fs)
+
+	fs = wantProjects(t, dir, "proj-1", "proj-2")
+	if err := fs.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	// Closing compacted everything into the snapshot
+	if info, err := os.Stat(filepath.Join(dir, journalFileName)); err != nil || info.Size() != 0 {
+		t.Errorf("journal after close: %v, %v; want it empty", info, err)
+	}
+	wantProjects(t, dir, "proj-1", "proj-2")
+}
+
+func TestFileStore_tornLine(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+	crash(t, fs)
+
+	line, err := encodeJournalLine(journalBatch{Seq: 2, Records: []Record{putRecord("project", "proj-2", &client.Project{ID: "proj-2"})}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendJournal(t, dir, line[:len(line)/2])
+
+	// The torn write is dropped, and later commits land after the last intact batch
+	fs = wantProjects(t, dir, "proj-1")
+	commitProject(t, fs, "proj-3")
+	crash(t, fs)
+
+	wantProjects(t, dir, "proj-1", "proj-3")
+}
+
+func TestFileStore_corruptLine(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+	commitProject(t, fs, "proj-2")
+	commitProject(t, fs, "proj-3")
+	crash(t, fs)
+
+	// Flip a byte in the payload of the second batch; it and every batch after it fail
+	// the checksum or follow it, and are dropped
+	path := filepath.Join(dir, journalFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[indexAfterLine(data, 1)+20] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs = wantProjects(t, dir, "proj-1")
+	commitProject(t, fs, "proj-4")
+	crash(t, fs)
+
+	wantProjects(t, dir, "proj-1", "proj-4")
+}
+
+// indexAfterLine returns the offset just past the first n lines of data.
+func indexAfterLine(data []byte, n int) int {
+	for i, b := range data {
+		if b == '\n' {
+			n--
+			if n == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+func TestFileStore_crashDuringCompaction(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+	commitProject(t, fs, "proj-2")
+
+	// Write the snapshot the way compact does, then crash before the journal is reset;
+	// the batches it covers must not be applied a second time
+	data, err := jsonSnapshot(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(dir, snapshotFileName, data); err != nil {
+		t.Fatal(err)
+	}
+	crash(t, fs)
+
+	fs = wantProjects(t, dir, "proj-1", "proj-2")
+	commitProject(t, fs, "proj-3")
+	crash(t, fs)
+
+	wantProjects(t, dir, "proj-1", "proj-2", "proj-3")
+}
+
+func TestFileStore_compactionKeepsJournalOnDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+	if err := fs.Compact(); err != nil {
+		t.Fatalf("Compact: %s", err)
+	}
+
+	// Commits after a compaction go to the journal that replaced the old one
+	open, err := fs.journal.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := os.Stat(filepath.Join(dir, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(open, onDisk) {
+		t.Error("store writes to a journal that is no longer on disk after compaction")
+	}
+
+	commitProject(t, fs, "proj-2")
+	crash(t, fs)
+
+	wantProjects(t, dir, "proj-1", "proj-2")
+}
+
+func TestFileStore_applyFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	commitProject(t, fs, "proj-1")
+
+	// The batch reaches the journal before the replica rejects it
+	if err := fs.Commit([]Record{{Op: opPut, Kind: "unknown", ID: "x"}}); err == nil {
+		t.Fatal("Commit of an unknown record kind succeeded, want an error")
+	}
+
+	// The store no longer persists anything once the journal and replica disagree
+	if err := fs.Commit(projectRecords("proj-2")); err == nil {
+		t.Error("Commit after a failed apply succeeded, want an error")
+	}
+	if err := fs.Compact(); err == nil {
+		t.Error("Compact after a failed apply succeeded, want an error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, snapshotFileName)); !os.IsNotExist(err) {
+		t.Errorf("snapshot written after a failed apply: %v", err)
+	}
+	crash(t, fs)
+
+	// Recovery fails on the same batch rather than silently dropping it
+	if _, err := OpenFileStore(dir); err == nil {
+		t.Error("OpenFileStore succeeded, want the unapplicable batch reported")
+	}
+}
+
+// jsonSnapshot encodes the snapshot file compact would write for fs.
+func jsonSnapshot(fs *FileStore) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return json.Marshal(fileSnapshot{Seq: fs.seq, State: fs.state.snapshot()})
+}
+
+func TestFileStore_concurrentCommits(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	fs.CompactEvery = 7
+
+	var wg sync.WaitGroup
+	var want []string
+	for i := range 8 {
+		for j := range 10 {
+			want = append(want, fmt.Sprintf("proj-%d-%d", i, j))
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				id := fmt.Sprintf("proj-%d-%d", i, j)
+				if err := fs.Commit(projectRecords(id)); err != nil {
+					t.Errorf("committing project %s: %s", id, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	crash(t, fs)
+
+	fs = wantProjects(t, dir, want...)
+	if fs.seq != 80 {
+		t.Errorf("recovered sequence %d, want 80", fs.seq)
+	}
+}
+
+func TestFileStore_compactionFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	fs.CompactEvery = 1
+
+	// A non-empty directory in place of the snapshot makes every compaction fail
+	blocker := filepath.Join(dir, snapshotFileName)
+	if err := os.MkdirAll(filepath.Join(blocker, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The batch is durable, so the commit succeeds and the replica has the change
+	commitProject(t, fs, "proj-1")
+	state, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if _, ok := state.Projects["proj-1"]; !ok {
+		t.Error("committed project missing from the store's state after a failed compaction")
+	}
+	if fs.pending != 1 {
+		t.Errorf("pending = %d, want the commit still awaiting compaction", fs.pending)
+	}
+
+	// Compaction is retried by the next commit once it can succeed
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	commitProject(t, fs, "proj-2")
+	if fs.pending != 0 {
+		t.Errorf("pending = %d after a successful compaction, want 0", fs.pending)
+	}
+	crash(t, fs)
+
+	wantProjects(t, dir, "proj-1", "proj-2")
+}
+
+func TestServer_commitSurvivesCompactionFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir)
+	fs.CompactEvery = 1
+	if err := os.MkdirAll(filepath.Join(dir, snapshotFileName, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewWithStore(Options{}, fs)
+	if err != nil {
+		t.Fatalf("NewWithStore: %s", err)
+	}
+
+	s.mu.Lock()
+	err = s.commit(putRecord("project", "proj-1", &client.Project{ID: "proj-1"}))
+	_, ok := s.state.Projects["proj-1"]
+	s.mu.Unlock()
+	if err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+	if !ok {
+		t.Error("committed project missing from the server's state")
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Fixtures describes resources to preload into a server. Instances reference their
+// project and objects their bucket by name, so fixture files never need to know IDs.
+type Fixtures struct {
+	Projects  []ProjectFixture  `json:"projects"`
+	Instances []InstanceFixture `json:"instances"`
+	Metadata  []MetadataFixture `json:"metadata"`
+	Buckets   []BucketFixture   `json:"buckets"`
+	Objects   []ObjectFixture   `json:"objects"`
+}
+
+// ProjectFixture describes a project. ID is optional and generated when empty.
+type ProjectFixture struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// InstanceFixture describes an instance in the project named Project. Omitted
+// attributes get the same defaults as the API.
+type InstanceFixture struct {
+	ID       string `json:"id,omitempty"`
+	Project  string `json:"project"`
+	Name     string `json:"name"`
+	CPU      int    `json:"cpu,omitempty"`
+	MemoryMB int    `json:"memory_mb,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Status   string `json:"status,omitempty"`
+}
+
+// MetadataFixture describes a metadata entry.
+type MetadataFixture struct {
+	ID     string `json:"id,omitempty"`
+	Path   string `json:"path"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// BucketFixture describes a bucket.
+type BucketFixture struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// ObjectFixture describes an object in the bucket named Bucket. Exactly one of
+// Content (plain text), ContentBase64 or ContentFile (a path relative to the fixture
+// file) provides its content.
+type ObjectFixture struct {
+	ID            string `json:"id,omitempty"`
+	Bucket        string `json:"bucket"`
+	Path          string `json:"path"`
+	Content       string `json:"content,omitempty"`
+	ContentBase64 string `json:"content_base64,omitempty"`
+	ContentFile   string `json:"content_file,omitempty"`
+}
+
+// LoadFixturesFile reads fixtures from a JSON file, inlining object content_file
+// references relative to the file's directory.
+func LoadFixturesFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("decoding fixtures %s: %w", path, err)
+	}
+
+	if err := fixtures.inlineFiles(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	return &fixtures, nil
+}
+
+// inlineFiles replaces object content_file references with their base64 content.
+// Relative paths are resolved against dir.
+func (f *Fixtures) inlineFiles(dir string) error {
+	for i := range f.Objects {
+		o := &f.Objects[i]
+		if o.ContentFile == "" {
+			continue
+		}
+
+		file := o.ContentFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("object %q: reading content_file: %w", o.Path, err)
+		}
+
+		o.ContentBase64 = base64.StdEncoding.EncodeToString(data)
+		o.ContentFile = ""
+	}
+
+	return nil
+}
+
+// ApplyFixtures creates the resources described by fixtures on top of the current
+// state. Either every fixture is applied or, on error, none is.
+func (s *Server) ApplyFixtures(fixtures *Fixtures) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	staged := newState()
+
+	projectIDs := map[string]string{}
+	for _, p := range s.state.Projects {
+		projectIDs[p.Name] = p.ID
+	}
+	for _, f := range fixtures.Projects {
+		if f.Name == "" {
+			return fmt.Errorf("project fixture without name")
+		}
+		if _, ok := projectIDs[f.Name]; ok {
+			return fmt.Errorf("project %q already exists", f.Name)
+		}
+
+		id, err := fixtureID(s, "project", f.ID, staged.Projects)
+		if err != nil {
+			return err
+		}
+		staged.Projects[id] = &client.Project{ID: id, Name: f.Name, CreatedAt: now, UpdatedAt: now}
+		projectIDs[f.Name] = id
+	}
+
+	for _, f := range fixtures.Instances {
+		projectID, ok := projectIDs[f.Project]
+		if !ok {
+			return fmt.Errorf("instance %q references unknown project %q", f.Name, f.Project)
+		}
+		if f.Name == "" {
+			return fmt.Errorf("instance fixture in project %q without name", f.Project)
+		}
+
+		instance := &client.Instance{
+			ProjectID: projectID,
+			Name:      f.Name,
+			CPU:       f.CPU,
+			MemoryMB:  f.MemoryMB,
+			Image:     f.Image,
+			Status:    f.Status,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if instance.CPU == 0 {
+			instance.CPU = 2
+		}
+		if instance.MemoryMB == 0 {
+			instance.MemoryMB = 2048
+		}
+		if instance.Image == "" {
+			instance.Image = "ubuntu:20.04"
+		}
+		if instance.Status == "" {
+			instance.Status = "running"
+		}
+		if !validStatus(instance.Status) {
+			return fmt.Errorf("instance %q: invalid status %q", f.Name, instance.Status)
+		}
+
+		id, err := fixtureID(s, "instance", f.ID, staged.Instances)
+		if err != nil {
+			return err
+		}
+		instance.ID = id
+		staged.Instances[id] = instance
+	}
+
+	paths := map[string]bool{}
+	for _, m := range s.state.Metadata {
+		paths[m.Path] = true
+	}
+	for _, f := range fixtures.Metadata {
+		if f.Path == "" {
+			return fmt.Errorf("metadata fixture without path")
+		}
+		if err := client.ValidateMetadataPath(f.Path); err != nil {
+			return fmt.Errorf("metadata %q: %w", f.Path, err)
+		}
+		if paths[f.Path] {
+			return fmt.Errorf("metadata %q already exists", f.Path)
+		}
+
+		id, err := fixtureID(s, "metadata", f.ID, staged.Metadata)
+		if err != nil {
+			return err
+		}
+		staged.Metadata[id] = &client.Metadata{
+			ID:          id,
+			Path:        f.Path,
+			Value:       f.Value,
+			Secret:      f.Secret,
+			ValueSHA256: valueSHA256(f.Value),
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		version := newMetadataVersion(*staged.Metadata[id])
+		staged.MetadataVersions[metadataVersionKey(id, 1)] = &version
+		paths[f.Path] = true
+	}
+
+	bucketIDs := map[string]string{}
+	for _, b := range s.state.Buckets {
+		bucketIDs[b.Name] = b.ID
+	}
+	for _, f := range fixtures.Buckets {
+		if msg := validateBucketName(f.Name); msg != "" {
+			return fmt.Errorf("bucket %q: %s", f.Name, msg)
+		}
+		if _, ok := bucketIDs[f.Name]; ok {
+			return fmt.Errorf("bucket %q already exists", f.Name)
+		}
+
+		id, err := fixtureID(s, "bucket", f.ID, staged.Buckets)
+		if err != nil {
+			return err
+		}
+		staged.Buckets[id] = &client.Bucket{ID: id, Name: f.Name, CreatedAt: now, UpdatedAt: now}
+		bucketIDs[f.Name] = id
+	}
+
+	for _, f := range fixtures.Objects {
+		bucketID, ok := bucketIDs[f.Bucket]
+		if !ok {
+			return fmt.Errorf("object %q references unknown bucket %q", f.Path, f.Bucket)
+		}
+
+		content, err := f.content()
+		if err != nil {
+			return err
+		}
+		if msg := validateObject(f.Path, content); msg != "" {
+			return fmt.Errorf("object %q: %s", f.Path, msg)
+		}
+		if s.objectByPath(bucketID, f.Path) != nil {
+			return fmt.Errorf("object %q already exists in bucket %q", f.Path, f.Bucket)
+		}
+		for _, o := range staged.Objects {
+			if o.BucketID == bucketID && o.Path == f.Path {
+				return fmt.Errorf("duplicate object %q in bucket %q", f.Path, f.Bucket)
+			}
+		}
+
+		id, err := fixtureID(s, "object", f.ID, staged.Objects)
+		if err != nil {
+			return err
+		}
+		staged.Objects[id] = &client.Object{ID: id, BucketID: bucketID, Path: f.Path, Content: content, CreatedAt: now, UpdatedAt: now}
+	}
+
+	var created []client.MetadataEvent
+	for _, m := range staged.snapshot().Metadata {
+		created = append(created, metadataChange(client.MetadataEventCreate, m))
+	}
+
+	// records starts by clearing the state; skip that so fixtures are added on top.
+	return s.commit(append(staged.records()[1:], s.metadataEventRecords(created...)...)...)
+}
+
+// fixtureID returns the explicit fixture ID after checking it is free, or a newly
+// generated one. It must be called with s.mu held.
+func fixtureID[T any](s *Server, kind, explicit string, staged map[string]*T) (string, error) {
+	if explicit == "" {
+		for {
+			id := s.newID(kind)
+			if _, ok := staged[id]; !ok {
+				return id, nil
+			}
+		}
+	}
+
+	if _, ok := staged[explicit]; ok || s.idTaken(kind, explicit) {
+		return "", fmt.Errorf("duplicate %s id %q", kind, explicit)
+	}
+
+	return explicit, nil
+}
+
+// content returns the base64 content of an object fixture.
+func (f ObjectFixture) content() (string, error) {
+	set := 0
+	for _, v := range []string{f.Content, f.ContentBase64, f.ContentFile} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", fmt.Errorf("object %q: only one of content, content_base64 and content_file may be set", f.Path)
+	}
+
+	if f.ContentFile != "" {
+		return "", fmt.Errorf("object %q: content_file is only supported when loading fixtures from a file", f.Path)
+	}
+	if f.ContentBase64 != "" {
+		return f.ContentBase64, nil
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(f.Content)), nil
+}
+
+func (s *Server) fixturesHandler(w http.ResponseWriter, r *http.Request) {
+	var fixtures Fixtures
+	if !decode(w, r, &fixtures) {
+		return
+	}
+
+	if err := s.ApplyFixtures(&fixtures); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_fixtures", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// validStatus reports whether status is an accepted instance status.
+func validStatus(status string) bool {
+	return status == "running" || status == "stopped"
+}
+
+func (s *Server) createInstance(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateInstanceRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "name is required")
+		return
+	}
+	if req.CPU == 0 {
+		req.CPU = 2
+	}
+	if req.MemoryMB == 0 {
+		req.MemoryMB = 2048
+	}
+	if req.Image == "" {
+		req.Image = "ubuntu:20.04"
+	}
+	if req.Status == "" {
+		req.Status = "running"
+	}
+	if req.CPU < 0 || req.MemoryMB < 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "cpu and memory_mb must be positive")
+		return
+	}
+	if !validStatus(req.Status) {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid status %q, expected running or stopped", req.Status))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.state.Projects[req.ProjectID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("project %q does not exist", req.ProjectID))
+		return
+	}
+	if !s.canAccessProject(w, r, "create instances in", project) {
+		return
+	}
+
+	if err := s.checkQuota(s.projectUsage(req.ProjectID, ""), req.CPU, req.MemoryMB); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "quota_exceeded", err.Error())
+		return
+	}
+
+	now := s.now()
+	instance := &client.Instance{
+		ID:        s.newID("instance"),
+		ProjectID: req.ProjectID,
+		Name:      req.Name,
+		CPU:       req.CPU,
+		MemoryMB:  req.MemoryMB,
+		Image:     req.Image,
+		Status:    req.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !s.save(w, putRecord("instance", instance.ID, instance), s.event(r, "create", "instance", instance.ID, nil, instance)) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, instance)
+}
+
+func (s *Server) listInstances(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instances := []client.Instance{}
+	for _, i := range sortedValues(s.state.Instances, func(i *client.Instance) time.Time { return i.CreatedAt }, func(i *client.Instance) string { return i.ID }) {
+		if v := q.Get("project_id"); v != "" && i.ProjectID != v {
+			continue
+		}
+		if v := q.Get("name"); v != "" && i.Name != v {
+			continue
+		}
+		if v := q.Get("status"); v != "" && i.Status != v {
+			continue
+		}
+		if !s.visibleProject(r, i.ProjectID) {
+			continue
+		}
+		instances = append(instances, i)
+	}
+
+	writeJSON(w, http.StatusOK, instances)
+}
+
+func (s *Server) getInstance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "instance not found")
+		return
+	}
+	if !s.canAccessInstance(w, r, "read", instance) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, instance)
+}
+
+func (s *Server) updateInstance(w http.ResponseWriter, r *http.Request) {
+	var req client.UpdateInstanceRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "instance not found")
+		return
+	}
+	if !s.canAccessInstance(w, r, "update", instance) {
+		return
+	}
+
+	if req.Image != nil && *req.Image != instance.Image {
+		writeError(w, http.StatusBadRequest, "immutable_field",
+			fmt.Sprintf("image is immutable (current: %q, requested: %q); replace the instance instead", instance.Image, *req.Image))
+		return
+	}
+	if req.Status != nil && !validStatus(*req.Status) {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid status %q, expected running or stopped", *req.Status))
+		return
+	}
+
+	updated := *instance
+	if req.Name != nil {
+		updated.Name = *req.Name
+	}
+	if req.CPU != nil {
+		updated.CPU = *req.CPU
+	}
+	if req.MemoryMB != nil {
+		updated.MemoryMB = *req.MemoryMB
+	}
+	if req.Status != nil {
+		updated.Status = *req.Status
+	}
+
+	if updated.CPU > instance.CPU || updated.MemoryMB > instance.MemoryMB {
+		if err := s.checkQuota(s.projectUsage(instance.ProjectID, instance.ID), updated.CPU, updated.MemoryMB); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "quota_exceeded", err.Error())
+			return
+		}
+	}
+
+	updated.UpdatedAt = s.now()
+	if !s.save(w, putRecord("instance", instance.ID, updated), s.event(r, "update", "instance", instance.ID, instance, updated)) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteInstance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "instance not found")
+		return
+	}
+	if !s.canAccessInstance(w, r, "delete", instance) {
+		return
+	}
+
+	if !s.save(w, deleteRecord("instance", instance.ID), s.event(r, "delete", "instance", instance.ID, instance, nil)) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
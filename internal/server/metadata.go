@@ -0,0 +1,368 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func (s *Server) createMetadata(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateMetadataRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.Path == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "path is required")
+		return
+	}
+	if err := client.ValidateMetadataPath(req.Path); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	ttl := &req.TTL
+	if req.TTL == 0 {
+		ttl = nil
+	}
+	expiresAt, msg := metadataExpiry(ttl, req.ExpiresAt, now)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+
+	// An expired entry gives up its path, and is recorded as deleted
+	var records []Record
+	var changes []client.MetadataEvent
+	if existing := s.metadataByPath(req.Path); existing != nil {
+		if !metadataExpired(existing, now) {
+			writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("metadata with path %q already exists", req.Path))
+			return
+		}
+		records = append(s.deleteMetadataRecords(existing), s.event(r, "delete", "metadata", existing.ID, redactSecret(*existing), nil))
+		changes = append(changes, metadataChange(client.MetadataEventDelete, *existing))
+	}
+
+	metadata := &client.Metadata{
+		ID:          s.newID("metadata"),
+		Path:        req.Path,
+		Value:       req.Value,
+		Secret:      req.Secret,
+		ValueSHA256: valueSHA256(req.Value),
+		Version:     1,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	changes = append(changes, metadataChange(client.MetadataEventCreate, *metadata))
+	records = append(records, putRecord("metadata", metadata.ID, metadata), metadataVersionRecord(newMetadataVersion(*metadata)), s.event(r, "create", "metadata", metadata.ID, nil, redactSecret(*metadata)))
+	records = append(records, s.metadataEventRecords(changes...)...)
+	if !s.save(w, records...) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, metadata)
+}
+
+func (s *Server) listMetadata(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entries := []client.Metadata{}
+	for _, m := range s.state.Metadata {
+		if strings.HasPrefix(m.Path, prefix) && !metadataExpired(m, now) {
+			entries = append(entries, *m)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) getMetadata(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metadata, ok := s.liveMetadata(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, metadata)
+}
+
+func (s *Server) updateMetadata(w http.ResponseWriter, r *http.Request) {
+	var req client.UpdateMetadataRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metadata, ok := s.liveMetadata(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if req.IfVersion != nil && *req.IfVersion != metadata.Version {
+		writeError(w, http.StatusPreconditionFailed, "precondition_failed", fmt.Sprintf("metadata is at version %d, not %d", metadata.Version, *req.IfVersion))
+		return
+	}
+	if req.IfValue != nil && *req.IfValue != metadata.Value {
+		writeError(w, http.StatusPreconditionFailed, "precondition_failed", "metadata value does not match if_value")
+		return
+	}
+
+	now := s.now()
+	var records []Record
+	var changes []client.MetadataEvent
+	updated := *metadata
+	if req.Path != nil && *req.Path != metadata.Path {
+		if *req.Path == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", "path must not be empty")
+			return
+		}
+		if err := client.ValidateMetadataPath(*req.Path); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if existing := s.metadataByPath(*req.Path); existing != nil {
+			if !metadataExpired(existing, now) {
+				writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("metadata with path %q already exists", *req.Path))
+				return
+			}
+			records = append(s.deleteMetadataRecords(existing), s.event(r, "delete", "metadata", existing.ID, redactSecret(*existing), nil))
+			changes = append(changes, metadataChange(client.MetadataEventDelete, *existing))
+		}
+		updated.Path = *req.Path
+	}
+	if req.TTL != nil || req.ExpiresAt != nil {
+		expiresAt, msg := metadataExpiry(req.TTL, req.ExpiresAt, now)
+		if msg != "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", msg)
+			return
+		}
+		updated.ExpiresAt = expiresAt
+	}
+	if req.Value != nil {
+		updated.Value = *req.Value
+		updated.ValueSHA256 = valueSHA256(*req.Value)
+	}
+	if req.Secret != nil {
+		updated.Secret = *req.Secret
+	}
+	updated.Version = metadata.Version + 1
+	updated.UpdatedAt = now
+	changes = append(changes, metadataChange(client.MetadataEventUpdate, updated))
+	records = append(records, putRecord("metadata", metadata.ID, updated), metadataVersionRecord(newMetadataVersion(updated)), s.event(r, "update", "metadata", metadata.ID, redactSecret(*metadata), redactSecret(updated)))
+	records = append(records, s.metadataEventRecords(changes...)...)
+	if !s.save(w, records...) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteMetadata(w http.ResponseWriter, r *http.Request) {
+	var ifVersion *int
+	if v := r.URL.Query().Get("if_version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid if_version %q", v))
+			return
+		}
+		ifVersion = &version
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metadata, ok := s.state.Metadata[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "metadata not found")
+		return
+	}
+	if ifVersion != nil && *ifVersion != metadata.Version {
+		writeError(w, http.StatusPreconditionFailed, "precondition_failed", fmt.Sprintf("metadata is at version %d, not %d", metadata.Version, *ifVersion))
+		return
+	}
+
+	// Expired metadata can still be deleted, which is a no-op for clients
+	records := append(s.deleteMetadataRecords(metadata), s.event(r, "delete", "metadata", metadata.ID, redactSecret(*metadata), nil))
+	records = append(records, s.metadataEventRecords(metadataChange(client.MetadataEventDelete, *metadata))...)
+	if !s.save(w, records...) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listMetadataVersions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metadata, ok := s.state.Metadata[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "metadata not found")
+		return
+	}
+
+	versions := []client.MetadataVersion{}
+	for _, v := range s.state.MetadataVersions {
+		if v.MetadataID == metadata.ID {
+			versions = append(versions, redactSecretVersion(r, *v))
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+
+	writeJSON(w, http.StatusOK, versions)
+}
+
+func (s *Server) getMetadataVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid version %q", r.PathValue("version")))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.state.MetadataVersions[metadataVersionKey(r.PathValue("id"), version)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "metadata version not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, redactSecretVersion(r, *v))
+}
+
+// liveMetadata returns the metadata with the given ID, writing a 404 response if it does
+// not exist or has expired. It must be called with s.mu held.
+func (s *Server) liveMetadata(w http.ResponseWriter, id string) (*client.Metadata, bool) {
+	metadata, ok := s.state.Metadata[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "metadata not found")
+		return nil, false
+	}
+	if metadataExpired(metadata, s.now()) {
+		writeError(w, http.StatusNotFound, "expired", fmt.Sprintf("metadata expired at %s", metadata.ExpiresAt.Format(time.RFC3339)))
+		return nil, false
+	}
+	return metadata, true
+}
+
+// deleteMetadataRecords returns the records deleting m and its versions. It must be
+// called with s.mu held.
+func (s *Server) deleteMetadataRecords(m *client.Metadata) []Record {
+	records := []Record{deleteRecord("metadata", m.ID)}
+	for key, v := range s.state.MetadataVersions {
+		if v.MetadataID == m.ID {
+			records = append(records, deleteRecord("metadata_version", key))
+		}
+	}
+	return records
+}
+
+// metadataExpired reports whether m has expired at now. Expired metadata is kept until
+// it is deleted or its path is reused, so reads can tell it expired rather than vanished.
+func metadataExpired(m *client.Metadata, now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// metadataExpiry returns the expiry time requested by ttl or expiresAt, or nil for
+// none; a ttl of 0 removes the expiry. It returns an error message for invalid requests.
+func metadataExpiry(ttl *int, expiresAt *time.Time, now time.Time) (*time.Time, string) {
+	switch {
+	case ttl != nil && expiresAt != nil:
+		return nil, "ttl and expires_at are mutually exclusive"
+	case ttl != nil && *ttl < 0:
+		return nil, "ttl must not be negative"
+	case ttl != nil && *ttl == 0:
+		return nil, ""
+	case ttl != nil:
+		t := now.Add(time.Duration(*ttl) * time.Second)
+		return &t, ""
+	case expiresAt != nil && !expiresAt.After(now):
+		return nil, "expires_at must be in the future"
+	case expiresAt != nil:
+		t := expiresAt.UTC()
+		return &t, ""
+	}
+	return nil, ""
+}
+
+// metadataByPath returns the metadata entry at path, or nil. It must be called with s.mu held.
+func (s *Server) metadataByPath(path string) *client.Metadata {
+	for _, m := range s.state.Metadata {
+		if m.Path == path {
+			return m
+		}
+	}
+	return nil
+}
+
+// newMetadataVersion returns the version of m as of its current state.
+func newMetadataVersion(m client.Metadata) client.MetadataVersion {
+	return client.MetadataVersion{
+		MetadataID:  m.ID,
+		Version:     m.Version,
+		Path:        m.Path,
+		Value:       m.Value,
+		Secret:      m.Secret,
+		ValueSHA256: m.ValueSHA256,
+		CreatedAt:   m.UpdatedAt,
+	}
+}
+
+// metadataVersionKey returns the key of a version in State.MetadataVersions.
+func metadataVersionKey(metadataID string, version int) string {
+	return fmt.Sprintf("%s@%d", metadataID, version)
+}
+
+// metadataVersionRecord returns a record storing v.
+func metadataVersionRecord(v client.MetadataVersion) Record {
+	return putRecord("metadata_version", metadataVersionKey(v.MetadataID, v.Version), v)
+}
+
+// valueSHA256 returns the hex-encoded SHA-256 digest of a metadata value, which clients
+// compare to detect drift of values they do not keep, such as write-only secrets.
+func valueSHA256(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// redactSecret returns m with its value removed if it is a secret, so that secrets do not
+// end up in the audit log. The digest is kept, so changes remain visible.
+func redactSecret(m client.Metadata) client.Metadata {
+	if m.Secret {
+		m.Value = ""
+	}
+	return m
+}
+
+// redactSecretVersion returns v with its value removed if it is a secret and the caller
+// may not write metadata, so that viewers cannot read past secret values either.
+func redactSecretVersion(r *http.Request, v client.MetadataVersion) client.MetadataVersion {
+	if token := principal(r); v.Secret && token != nil && token.Role.rank() < RoleEditor.rank() {
+		v.Value = ""
+	}
+	return v
+}
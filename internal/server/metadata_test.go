@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func TestMetadata_replacingExpiredEntryRecordsDelete(t *testing.T) {
+	clock := NewFakeClock(DefaultFakeClockStart)
+	srv := httptest.NewServer(New(Options{Clock: clock}))
+	t.Cleanup(srv.Close)
+	c := client.NewClient(srv.URL + "/v1")
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name    string
+		replace func(path string) (*client.Metadata, error)
+	}{
+		{
+			name: "create",
+			replace: func(path string) (*client.Metadata, error) {
+				return c.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path, Value: "new"})
+			},
+		},
+		{
+			name: "update",
+			replace: func(path string) (*client.Metadata, error) {
+				m, err := c.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path + "-other", Value: "new"})
+				if err != nil {
+					return nil, err
+				}
+				return c.UpdateMetadata(ctx, m.ID, client.UpdateMetadataRequest{Path: &path})
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := "app/" + tc.name
+			expired, err := c.CreateMetadata(ctx, client.CreateMetadataRequest{Path: path, Value: "s3cret", Secret: true, TTL: 60})
+			if err != nil {
+				t.Fatalf("CreateMetadata: %s", err)
+			}
+			clock.Advance(time.Hour)
+
+			replacement, err := tc.replace(path)
+			if err != nil {
+				t.Fatalf("replacing expired metadata: %s", err)
+			}
+
+			// The expired entry is recorded as deleted, redacted, before its path is reused
+			events, err := c.ListEvents(ctx, client.EventFilter{ResourceType: "metadata"})
+			if err != nil {
+				t.Fatalf("ListEvents: %s", err)
+			}
+			deleted, replaced := -1, -1
+			for i, e := range events {
+				switch {
+				case e.ResourceID == expired.ID && e.Action == "delete":
+					deleted = i
+					if e.Before == nil || e.Before["value"] != "" || e.Before["secret"] != true {
+						t.Errorf("delete event before = %v, want the redacted secret", e.Before)
+					}
+				case e.ResourceID == replacement.ID && e.After != nil && e.After["path"] == path:
+					replaced = i
+				}
+			}
+			if deleted < 0 || replaced < 0 || deleted > replaced {
+				t.Errorf("delete event at %d and replacing event at %d in %+v, want the delete first", deleted, replaced, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateProjectRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "name is required")
+		return
+	}
+
+	if token := principal(r); token != nil && !token.allowsProject(&client.Project{Name: req.Name}) {
+		forbid(w, token, "create", fmt.Sprintf("project %q", req.Name))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.projectByName(req.Name) != nil {
+		writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("project with name %q already exists", req.Name))
+		return
+	}
+
+	now := s.now()
+	project := &client.Project{
+		ID:        s.newID("project"),
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !s.save(w, putRecord("project", project.ID, project), s.event(r, "create", "project", project.ID, nil, project)) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, project)
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := []client.Project{}
+	for _, p := range sortedValues(s.state.Projects, func(p *client.Project) time.Time { return p.CreatedAt }, func(p *client.Project) string { return p.ID }) {
+		if name != "" && p.Name != name {
+			continue
+		}
+		if !s.visibleProject(r, p.ID) {
+			continue
+		}
+		projects = append(projects, p)
+	}
+
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "project not found")
+		return
+	}
+	if !s.canAccessProject(w, r, "read", project) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
+	var req client.UpdateProjectRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "project not found")
+		return
+	}
+	if !s.canAccessProject(w, r, "update", project) {
+		return
+	}
+
+	updated := *project
+	if req.Name != "" && req.Name != project.Name {
+		if s.projectByName(req.Name) != nil {
+			writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("project with name %q already exists", req.Name))
+			return
+		}
+		updated.Name = req.Name
+	}
+	updated.UpdatedAt = s.now()
+	if !s.save(w, putRecord("project", project.ID, updated), s.event(r, "update", "project", project.ID, project, updated)) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "project not found")
+		return
+	}
+	if !s.canAccessProject(w, r, "delete", project) {
+		return
+	}
+
+	for _, instance := range s.state.Instances {
+		if instance.ProjectID == project.ID {
+			writeError(w, http.StatusConflict, "conflict", fmt.Sprintf("project %s still has instances", project.ID))
+			return
+		}
+	}
+
+	if !s.save(w, deleteRecord("project", project.ID), s.event(r, "delete", "project", project.ID, project, nil)) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getProjectQuota(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "project not found")
+		return
+	}
+	if !s.canAccessProject(w, r, "read quota of", project) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, client.ProjectQuota{
+		ProjectID: project.ID,
+		Limits:    s.opts.DefaultQuota,
+		Usage:     s.projectUsage(project.ID, ""),
+	})
+}
+
+// projectByName returns the project with the given name, or nil. It must be called with s.mu held.
+func (s *Server) projectByName(name string) *client.Project {
+	for _, p := range s.state.Projects {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// projectUsage sums the compute allocated to a project's instances, skipping the
+// instance with ID exclude. It must be called with s.mu held.
+func (s *Server) projectUsage(projectID, exclude string) client.ResourceAmounts {
+	var usage client.ResourceAmounts
+	for _, instance := range s.state.Instances {
+		if instance.ProjectID != projectID || instance.ID == exclude {
+			continue
+		}
+		usage.CPU += instance.CPU
+		usage.MemoryMB += instance.MemoryMB
+	}
+	return usage
+}
+
+// checkQuota reports whether adding cpu and memoryMB to usage stays within the default quota.
+func (s *Server) checkQuota(usage client.ResourceAmounts, cpu, memoryMB int) error {
+	limits := s.opts.DefaultQuota
+	if limits.CPU > 0 && usage.CPU+cpu > limits.CPU {
+		return fmt.Errorf("cpu quota exceeded: %d requested, %d of %d in use", cpu, usage.CPU, limits.CPU)
+	}
+	if limits.MemoryMB > 0 && usage.MemoryMB+memoryMB > limits.MemoryMB {
+		return fmt.Errorf("memory_mb quota exceeded: %d requested, %d of %d in use", memoryMB, usage.MemoryMB, limits.MemoryMB)
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package server implements an in-memory DirtCloud API that stands in for the full
+// dirtcloud-server during local development and tests. It speaks the same wire format
+// as internal/client and records an audit event for every change.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Options configures a Server.
+type Options struct {
+	// DefaultQuota is the compute quota applied to every project. Zero values mean unlimited.
+	DefaultQuota client.ResourceAmounts
+
+	// IDs generates resource IDs. Defaults to RandomIDs.
+	IDs IDGenerator
+
+	// Clock provides timestamps. Defaults to SystemClock.
+	Clock Clock
+
+	// Tokens lists the accepted API tokens and their roles. When nil, the server does
+	// not authenticate requests and every caller has full access.
+	Tokens *TokenConfig
+
+	// WatchHeartbeat is how often idle metadata watches are sent a heartbeat, so that
+	// clients and proxies can tell them from dead connections. Defaults to 15s.
+	WatchHeartbeat time.Duration
+}
+
+// State holds every resource known to the server.
+type State struct {
+	Projects  map[string]*client.Project
+	Instances map[string]*client.Instance
+	Metadata  map[string]*client.Metadata
+	Buckets   map[string]*client.Bucket
+	Objects   map[string]*client.Object
+	Events    []client.Event
+
+	// MetadataVersions holds every version of every metadata entry, keyed by
+	// metadataVersionKey.
+	MetadataVersions map[string]*client.MetadataVersion
+
+	// MetadataEvents holds the latest metadata changes in revision order, for watches to
+	// replay. At most metadataEventRetention are kept.
+	MetadataEvents []client.MetadataEvent
+}
+
+// newState returns an empty State.
+func newState() *State {
+	return &State{
+		Projects:  map[string]*client.Project{},
+		Instances: map[string]*client.Instance{},
+		Metadata:  map[string]*client.Metadata{},
+		Buckets:   map[string]*client.Bucket{},
+		Objects:   map[string]*client.Object{},
+
+		MetadataVersions: map[string]*client.MetadataVersion{},
+	}
+}
+
+// Server is an http.Handler serving the DirtCloud API under /v1.
+type Server struct {
+	mu    sync.Mutex
+	opts  Options
+	store Store
+	state *State
+	mux   *http.ServeMux
+
+	// changed is closed and replaced whenever metadata changes, to wake watches.
+	changed chan struct{}
+	// closed is closed by StopWatches.
+	closed      chan struct{}
+	stopWatches sync.Once
+}
+
+// New creates a Server with empty, in-memory state.
+func New(opts Options) *Server {
+	s, err := NewWithStore(opts, NewMemoryStore())
+	if err != nil {
+		// A MemoryStore always loads an empty state.
+		panic(err)
+	}
+	return s
+}
+
+// NewWithStore creates a Server whose state is loaded from and persisted to store.
+func NewWithStore(opts Options, store Store) (*Server, error) {
+	if opts.IDs == nil {
+		opts.IDs = RandomIDs{}
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock{}
+	}
+	if opts.WatchHeartbeat <= 0 {
+		opts.WatchHeartbeat = defaultWatchHeartbeat
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading state: %w", err)
+	}
+
+	s := &Server{
+		opts:  opts,
+		store: store,
+		state: state,
+		mux:   http.NewServeMux(),
+
+		changed: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+
+	s.handle("POST /v1/projects", RoleAdmin, "create", "project", s.createProject)
+	s.handle("GET /v1/projects", RoleViewer, "list", "projects", s.listProjects)
+	s.handle("GET /v1/projects/{id}", RoleViewer, "read", "project", s.getProject)
+	s.handle("PATCH /v1/projects/{id}", RoleEditor, "update", "project", s.updateProject)
+	s.handle("DELETE /v1/projects/{id}", RoleAdmin, "delete", "project", s.deleteProject)
+	s.handle("GET /v1/projects/{id}/quota", RoleViewer, "read quota of", "project", s.getProjectQuota)
+
+	s.handle("POST /v1/instances", RoleEditor, "create", "instance", s.createInstance)
+	s.handle("GET /v1/instances", RoleViewer, "list", "instances", s.listInstances)
+	s.handle("GET /v1/instances/{id}", RoleViewer, "read", "instance", s.getInstance)
+	s.handle("PATCH /v1/instances/{id}", RoleEditor, "update", "instance", s.updateInstance)
+	s.handle("DELETE /v1/instances/{id}", RoleEditor, "delete", "instance", s.deleteInstance)
+
+	s.handle("POST /v1/metadata", RoleEditor, "create", "metadata", s.createMetadata)
+	s.handle("GET /v1/metadata", RoleViewer, "list", "metadata", s.listMetadata)
+	s.handle("GET /v1/metadata/watch", RoleViewer, "watch", "metadata", s.watchMetadata)
+	s.handle("GET /v1/metadata/{id}", RoleViewer, "read", "metadata", s.getMetadata)
+	s.handle("PATCH /v1/metadata/{id}", RoleEditor, "update", "metadata", s.updateMetadata)
+	s.handle("DELETE /v1/metadata/{id}", RoleEditor, "delete", "metadata", s.deleteMetadata)
+	s.handle("GET /v1/metadata/{id}/versions", RoleViewer, "list versions of", "metadata", s.listMetadataVersions)
+	s.handle("GET /v1/metadata/{id}/versions/{version}", RoleViewer, "read a version of", "metadata", s.getMetadataVersion)
+
+	s.handle("POST /v1/buckets", RoleEditor, "create", "bucket", s.createBucket)
+	s.handle("GET /v1/buckets", RoleViewer, "list", "buckets", s.listBuckets)
+	s.handle("GET /v1/buckets/{id}", RoleViewer, "read", "bucket", s.getBucket)
+	s.handle("PATCH /v1/buckets/{id}", RoleEditor, "update", "bucket", s.updateBucket)
+	s.handle("DELETE /v1/buckets/{id}", RoleEditor, "delete", "bucket", s.deleteBucket)
+
+	s.handle("POST /v1/bucket/{bucket_id}/objects", RoleEditor, "create", "object", s.createObject)
+	s.handle("GET /v1/bucket/{bucket_id}/objects", RoleViewer, "list", "objects", s.listObjects)
+	s.handle("GET /v1/bucket/{bucket_id}/objects/{id}", RoleViewer, "read", "object", s.getObject)
+	s.handle("PATCH /v1/bucket/{bucket_id}/objects/{id}", RoleEditor, "update", "object", s.updateObject)
+	s.handle("DELETE /v1/bucket/{bucket_id}/objects/{id}", RoleEditor, "delete", "object", s.deleteObject)
+
+	s.handle("GET /v1/events", RoleViewer, "list", "events", s.listEvents)
+
+	s.handle("POST /v1/admin/snapshot", RoleAdmin, "snapshot", serverResource, s.snapshotHandler)
+	s.handle("POST /v1/admin/restore", RoleAdmin, "restore", serverResource, s.restoreHandler)
+	s.handle("POST /v1/admin/reset", RoleAdmin, "reset", serverResource, s.resetHandler)
+	s.handle("POST /v1/admin/fixtures", RoleAdmin, "load fixtures into", serverResource, s.fixturesHandler)
+	s.handle("POST /v1/admin/deterministic", RoleAdmin, "enable deterministic mode on", serverResource, s.deterministicHandler)
+	s.handle("GET /v1/admin/clock", RoleAdmin, "read the clock of", serverResource, s.getClockHandler)
+	s.handle("POST /v1/admin/clock", RoleAdmin, "set the clock of", serverResource, s.setClockHandler)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close ends open metadata watches and releases the server's store.
+func (s *Server) Close() error {
+	s.StopWatches()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.store.Close()
+}
+
+// now returns the current server time. It must be called with s.mu held.
+func (s *Server) now() time.Time {
+	return s.opts.Clock.Now()
+}
+
+// newID returns an unused ID for a new resource of the given kind. It must be called
+// with s.mu held.
+func (s *Server) newID(kind string) string {
+	for {
+		id := s.opts.IDs.NewID(kind)
+		if !s.idTaken(kind, id) {
+			return id
+		}
+	}
+}
+
+// idTaken reports whether id is already used by a resource of the given kind, which
+// happens when sequential IDs restart after a restore. It must be called with s.mu held.
+func (s *Server) idTaken(kind, id string) bool {
+	var ok bool
+	switch kind {
+	case "project":
+		_, ok = s.state.Projects[id]
+	case "instance":
+		_, ok = s.state.Instances[id]
+	case "metadata":
+		_, ok = s.state.Metadata[id]
+	case "bucket":
+		_, ok = s.state.Buckets[id]
+	case "object":
+		_, ok = s.state.Objects[id]
+	case "event":
+		for _, e := range s.state.Events {
+			if e.ID == id {
+				return true
+			}
+		}
+	}
+	return ok
+}
+
+// event returns a record appending an audit event for a change made by the given
+// request. It must be called with s.mu held. before and after are the resource before
+// and after the change and may be nil for creations and deletions respectively.
+func (s *Server) event(r *http.Request, action, resourceType, resourceID string, before, after interface{}) Record {
+	return eventRecord(client.Event{
+		ID:           s.newID("event"),
+		Actor:        actor(r),
+		Source:       source(r),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       fields(before),
+		After:        fields(after),
+		Timestamp:    s.now(),
+	})
+}
+
+// save commits records, writing a 500 response if the store fails. It must be called
+// with s.mu held.
+func (s *Server) save(w http.ResponseWriter, records ...Record) bool {
+	if err := s.commit(records...); err != nil {
+		writeError(w, http.StatusInternalServerError, "storage_error", err.Error())
+		return false
+	}
+	return true
+}
+
+// actor identifies the caller of a request. Authenticated callers are identified by
+// their token name. Otherwise an explicit X-Dirt-Actor header wins, then a fingerprint
+// of the bearer token.
+func actor(r *http.Request) string {
+	if token := principal(r); token != nil {
+		return token.Name
+	}
+	if name := r.Header.Get("X-Dirt-Actor"); name != "" {
+		return name
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return "anonymous"
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// source returns the client a request was made through.
+func source(r *http.Request) string {
+	if ua := r.UserAgent(); ua != "" {
+		return ua
+	}
+	return "unknown"
+}
+
+// fields converts a resource into a generic field map for audit events.
+func fields(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil
+	}
+
+	return out
+}
+
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var since, until time.Time
+	for name, target := range map[string]*time.Time{"since": &since, "until": &until} {
+		if raw := q.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("%s must be an RFC 3339 timestamp", name))
+				return
+			}
+			*target = t
+		}
+	}
+
+	s.mu.Lock()
+	events := make([]client.Event, 0, len(s.state.Events))
+	for _, e := range s.state.Events {
+		if !s.visibleEvent(r, e) {
+			continue
+		}
+		if id := q.Get("resource_id"); id != "" && e.ResourceID != id {
+			continue
+		}
+		if typ := q.Get("resource_type"); typ != "" && e.ResourceType != typ {
+			continue
+		}
+		if action := q.Get("action"); action != "" && e.Action != action {
+			continue
+		}
+		if !since.IsZero() && e.Timestamp.Before(since) {
+			continue
+		}
+		if !until.IsZero() && e.Timestamp.After(until) {
+			continue
+		}
+		events = append(events, e)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, events)
+}
+
+// decode reads a JSON request body into v, writing a 400 response on failure.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid JSON body: %s", err))
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the client.ErrorResponse format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, client.ErrorResponse{Error: code, Message: message})
+}
+
+// sortedValues returns the values of m ordered by creation time, then ID.
+func sortedValues[T any](m map[string]*T, created func(*T) time.Time, id func(*T) string) []T {
+	ptrs := make([]*T, 0, len(m))
+	for _, v := range m {
+		ptrs = append(ptrs, v)
+	}
+
+	sort.Slice(ptrs, func(i, j int) bool {
+		ci, cj := created(ptrs[i]), created(ptrs[j])
+		if !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return id(ptrs[i]) < id(ptrs[j])
+	})
+
+	out := make([]T, 0, len(ptrs))
+	for _, v := range ptrs {
+		out = append(out, *v)
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// Record operations.
+const (
+	opPut    = "put"
+	opDelete = "delete"
+	opEvent  = "event"
+	opReset  = "reset"
+
+	opMetadataEvent = "metadata_event"
+)
+
+// metadataEventRetention is the number of metadata changes kept for watches to replay.
+// Watches resuming from an older revision are answered with a 410.
+const metadataEventRetention = 1000
+
+// Record is a single state change. Every change the server makes is expressed as a
+// batch of records that a Store persists atomically before the change becomes visible.
+type Record struct {
+	Op    string          `json:"op"`
+	Kind  string          `json:"kind,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Store persists server state.
+type Store interface {
+	// Load returns the persisted state, or an empty state for a new store.
+	Load() (*State, error)
+	// Commit durably persists a batch of records. Either the whole batch survives a
+	// crash or none of it does.
+	Commit(records []Record) error
+	// Close flushes and releases the store.
+	Close() error
+}
+
+// MemoryStore keeps nothing beyond the server's own in-memory state; everything is
+// lost when the process exits. It is the default Store.
+type MemoryStore struct{}
+
+// NewMemoryStore returns a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load implements Store.
+func (*MemoryStore) Load() (*State, error) { return newState(), nil }
+
+// Commit implements Store.
+func (*MemoryStore) Commit([]Record) error { return nil }
+
+// Close implements Store.
+func (*MemoryStore) Close() error { return nil }
+
+// putRecord returns a record storing v as the resource kind/id.
+func putRecord(kind, id string, v interface{}) Record {
+	value, err := json.Marshal(v)
+	if err != nil {
+		// All stored values are plain client model structs, which always marshal.
+		panic(fmt.Sprintf("marshaling %s %s: %s", kind, id, err))
+	}
+	return Record{Op: opPut, Kind: kind, ID: id, Value: value}
+}
+
+// deleteRecord returns a record removing the resource kind/id.
+func deleteRecord(kind, id string) Record {
+	return Record{Op: opDelete, Kind: kind, ID: id}
+}
+
+// resetRecord returns a record clearing all state.
+func resetRecord() Record {
+	return Record{Op: opReset}
+}
+
+// eventRecord returns a record appending an audit event.
+func eventRecord(e client.Event) Record {
+	r := putRecord("event", e.ID, e)
+	r.Op = opEvent
+	return r
+}
+
+// metadataEventRecord returns a record appending a metadata change.
+func metadataEventRecord(e client.MetadataEvent) Record {
+	r := putRecord("metadata_event", strconv.FormatInt(e.Revision, 10), e)
+	r.Op = opMetadataEvent
+	return r
+}
+
+// apply applies a single record to st.
+func (st *State) apply(rec Record) error {
+	switch rec.Op {
+	case opReset:
+		*st = *newState()
+		return nil
+	case opEvent:
+		var e client.Event
+		if err := json.Unmarshal(rec.Value, &e); err != nil {
+			return fmt.Errorf("decoding event %s: %w", rec.ID, err)
+		}
+		st.Events = append(st.Events, e)
+		return nil
+	case opMetadataEvent:
+		var e client.MetadataEvent
+		if err := json.Unmarshal(rec.Value, &e); err != nil {
+			return fmt.Errorf("decoding metadata event %s: %w", rec.ID, err)
+		}
+		st.MetadataEvents = append(st.MetadataEvents, e)
+		if n := len(st.MetadataEvents) - metadataEventRetention; n > 0 {
+			st.MetadataEvents = append([]client.MetadataEvent(nil), st.MetadataEvents[n:]...)
+		}
+		return nil
+	case opPut, opDelete:
+	default:
+		return fmt.Errorf("unknown record op %q", rec.Op)
+	}
+
+	switch rec.Kind {
+	case "project":
+		return applyTo(st.Projects, rec)
+	case "instance":
+		return applyTo(st.Instances, rec)
+	case "metadata":
+		return applyTo(st.Metadata, rec)
+	case "metadata_version":
+		return applyTo(st.MetadataVersions, rec)
+	case "bucket":
+		return applyTo(st.Buckets, rec)
+	case "object":
+		return applyTo(st.Objects, rec)
+	}
+
+	return fmt.Errorf("unknown record kind %q", rec.Kind)
+}
+
+// applyTo applies a put or delete record to the resource map m.
+func applyTo[T any](m map[string]*T, rec Record) error {
+	if rec.Op == opDelete {
+		delete(m, rec.ID)
+		return nil
+	}
+
+	v := new(T)
+	if err := json.Unmarshal(rec.Value, v); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", rec.Kind, rec.ID, err)
+	}
+	m[rec.ID] = v
+	return nil
+}
+
+// snapshot returns a portable copy of st.
+func (st *State) snapshot() client.Snapshot {
+	return client.Snapshot{
+		Version:   client.SnapshotFormatVersion,
+		Projects:  sortedValues(st.Projects, func(p *client.Project) time.Time { return p.CreatedAt }, func(p *client.Project) string { return p.ID }),
+		Instances: sortedValues(st.Instances, func(i *client.Instance) time.Time { return i.CreatedAt }, func(i *client.Instance) string { return i.ID }),
+		Metadata:  sortedValues(st.Metadata, func(m *client.Metadata) time.Time { return m.CreatedAt }, func(m *client.Metadata) string { return m.ID }),
+		Buckets:   sortedValues(st.Buckets, func(b *client.Bucket) time.Time { return b.CreatedAt }, func(b *client.Bucket) string { return b.ID }),
+		Objects:   sortedValues(st.Objects, func(o *client.Object) time.Time { return o.CreatedAt }, func(o *client.Object) string { return o.ID }),
+		Events:    append([]client.Event(nil), st.Events...),
+
+		MetadataVersions: sortedValues(st.MetadataVersions, func(v *client.MetadataVersion) time.Time { return v.CreatedAt }, func(v *client.MetadataVersion) string {
+			return metadataVersionKey(v.MetadataID, v.Version)
+		}),
+		MetadataEvents: append([]client.MetadataEvent(nil), st.MetadataEvents...),
+	}
+}
+
+// records returns the batch of records that rebuilds st from scratch.
+func (st *State) records() []Record {
+	snap := st.snapshot()
+
+	records := []Record{resetRecord()}
+	for _, p := range snap.Projects {
+		records = append(records, putRecord("project", p.ID, p))
+	}
+	for _, i := range snap.Instances {
+		records = append(records, putRecord("instance", i.ID, i))
+	}
+	for _, m := range snap.Metadata {
+		records = append(records, putRecord("metadata", m.ID, m))
+	}
+	for _, v := range snap.MetadataVersions {
+		records = append(records, metadataVersionRecord(v))
+	}
+	for _, b := range snap.Buckets {
+		records = append(records, putRecord("bucket", b.ID, b))
+	}
+	for _, o := range snap.Objects {
+		records = append(records, putRecord("object", o.ID, o))
+	}
+	for _, e := range snap.Events {
+		records = append(records, eventRecord(e))
+	}
+	for _, e := range snap.MetadataEvents {
+		records = append(records, metadataEventRecord(e))
+	}
+	return records
+}
+
+// commit persists records to the store and then applies them to the in-memory state,
+// so a change is only visible once it is durable. It must be called with s.mu held.
+func (s *Server) commit(records ...Record) error {
+	if err := s.store.Commit(records); err != nil {
+		return fmt.Errorf("persisting change: %w", err)
+	}
+
+	notify := false
+	for _, rec := range records {
+		if err := s.state.apply(rec); err != nil {
+			return err
+		}
+		notify = notify || rec.Op == opMetadataEvent
+	}
+	if notify {
+		s.notifyWatches()
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+// defaultWatchHeartbeat is how often idle watches are sent a heartbeat by default.
+const defaultWatchHeartbeat = 15 * time.Second
+
+// watchMetadata streams metadata changes as server-sent events, starting after the
+// revision in the since parameter or Last-Event-ID header, or after the latest revision.
+func (s *Server) watchMetadata(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	since := int64(-1)
+	start := r.URL.Query().Get("since")
+	if start == "" {
+		start = r.Header.Get("Last-Event-ID")
+	}
+	if start != "" {
+		revision, err := strconv.ParseInt(start, 10, 64)
+		if err != nil || revision < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid revision %q", start))
+			return
+		}
+		since = revision
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming_unsupported", "the connection does not support streaming")
+		return
+	}
+
+	select {
+	case <-s.closed:
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "the server is shutting down")
+		return
+	default:
+	}
+
+	s.mu.Lock()
+	latest := s.metadataRevision()
+	if since < 0 {
+		since = latest
+	}
+	if _, ok := s.metadataEventsAfter(since); !ok {
+		oldest := latest
+		if len(s.state.MetadataEvents) > 0 {
+			oldest = s.state.MetadataEvents[0].Revision - 1
+		}
+		s.mu.Unlock()
+		writeError(w, http.StatusGone, "revision_unavailable", fmt.Sprintf("revision %d is not available, watches can resume from revisions %d to %d", since, oldest, latest))
+		return
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Dirt-Revision", strconv.FormatInt(since, 10))
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.opts.WatchHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		s.mu.Lock()
+		events, ok := s.metadataEventsAfter(since)
+		changed := s.changed
+		s.mu.Unlock()
+
+		// The changes after since are gone; ending the stream makes the client resume
+		// and learn that from the 410
+		if !ok {
+			return
+		}
+
+		skipped := false
+		for _, e := range events {
+			since = e.Revision
+			if e.Type != client.MetadataEventReset && !strings.HasPrefix(e.Metadata.Path, prefix) {
+				skipped = true
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Revision, e.Type, data)
+			skipped = false
+		}
+		// Report progress past changes to other paths, so resuming does not replay them
+		if skipped {
+			fmt.Fprintf(w, "id: %d\n\n", since)
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		case <-changed:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// StopWatches ends every open metadata watch and refuses new ones. Call it before
+// shutting down an http.Server, which otherwise waits for the streams to end.
+func (s *Server) StopWatches() {
+	s.stopWatches.Do(func() { close(s.closed) })
+}
+
+// notifyWatches wakes every open metadata watch. It must be called with s.mu held.
+func (s *Server) notifyWatches() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// metadataRevision returns the revision of the latest metadata change, or 0 if there
+// has been none. It must be called with s.mu held.
+func (s *Server) metadataRevision() int64 {
+	if n := len(s.state.MetadataEvents); n > 0 {
+		return s.state.MetadataEvents[n-1].Revision
+	}
+	return 0
+}
+
+// metadataEventsAfter returns the metadata changes after revision since. It returns
+// false if some of them are no longer retained, or since is newer than the latest
+// revision. It must be called with s.mu held.
+func (s *Server) metadataEventsAfter(since int64) ([]client.MetadataEvent, bool) {
+	events := s.state.MetadataEvents
+	if since > s.metadataRevision() {
+		return nil, false
+	}
+	if len(events) == 0 {
+		return nil, true
+	}
+
+	i := since - (events[0].Revision - 1)
+	if i < 0 {
+		return nil, false
+	}
+	return append([]client.MetadataEvent(nil), events[i:]...), true
+}
+
+// metadataEventRecords returns the records appending events at the revisions following
+// the latest one. It must be called with s.mu held.
+func (s *Server) metadataEventRecords(events ...client.MetadataEvent) []Record {
+	revision := s.metadataRevision()
+	records := make([]Record, 0, len(events))
+	for _, e := range events {
+		revision++
+		e.Revision = revision
+		e.Timestamp = s.now()
+		records = append(records, metadataEventRecord(e))
+	}
+	return records
+}
+
+// metadataChange returns an event of the given type for m, to be numbered by
+// metadataEventRecords. Secret values are redacted like in the audit log, since any
+// viewer may watch.
+func metadataChange(eventType client.MetadataEventType, m client.Metadata) client.MetadataEvent {
+	m = redactSecret(m)
+	return client.MetadataEvent{Type: eventType, Metadata: &m}
+}
+
+// metadataResetRecords returns the records telling watches that all metadata was
+// replaced by metadata. It must be called with s.mu held.
+func (s *Server) metadataResetRecords(metadata []client.Metadata) []Record {
+	events := []client.MetadataEvent{{Type: client.MetadataEventReset}}
+	for _, m := range metadata {
+		events = append(events, metadataChange(client.MetadataEventCreate, m))
+	}
+	return s.metadataEventRecords(events...)
+}
+
+// resetRecords returns the records clearing all state. It must be called with s.mu held.
+func (s *Server) resetRecords() []Record {
+	return append([]Record{resetRecord()}, s.metadataResetRecords(nil)...)
+}
+
+// restoreRecords returns the records replacing all state with state. Watches see a
+// reset followed by the creation of every restored metadata entry, at revisions
+// following the current ones rather than those in state, so revisions never go back.
+// It must be called with s.mu held.
+func (s *Server) restoreRecords(state *State) []Record {
+	state.MetadataEvents = nil
+	return append(state.records(), s.metadataResetRecords(state.snapshot().Metadata)...)
+}
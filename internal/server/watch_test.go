@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/terraform-provider-dirt/internal/client"
+)
+
+func TestWatchMetadata_redactsSecrets(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}))
+	t.Cleanup(srv.Close)
+	c := client.NewClient(srv.URL + "/v1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watch, err := c.WatchMetadata(ctx, "app/", 0)
+	if err != nil {
+		t.Fatalf("WatchMetadata: %s", err)
+	}
+
+	metadata, err := c.CreateMetadata(ctx, client.CreateMetadataRequest{Path: "app/token", Value: "s3cret", Secret: true})
+	if err != nil {
+		t.Fatalf("CreateMetadata: %s", err)
+	}
+	value := "t0ken"
+	updated, err := c.UpdateMetadata(ctx, metadata.ID, client.UpdateMetadataRequest{Value: &value})
+	if err != nil {
+		t.Fatalf("UpdateMetadata: %s", err)
+	}
+	if err := c.DeleteMetadata(ctx, metadata.ID); err != nil {
+		t.Fatalf("DeleteMetadata: %s", err)
+	}
+
+	for _, want := range []struct {
+		eventType client.MetadataEventType
+		sha256    string
+	}{
+		{client.MetadataEventCreate, metadata.ValueSHA256},
+		{client.MetadataEventUpdate, updated.ValueSHA256},
+		{client.MetadataEventDelete, updated.ValueSHA256},
+	} {
+		var e client.MetadataEvent
+		select {
+		case e = <-watch.Events():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the %s event: %v", want.eventType, watch.Err())
+		}
+
+		if e.Type != want.eventType || e.Metadata == nil {
+			t.Fatalf("event = %+v, want a %s event with metadata", e, want.eventType)
+		}
+		if e.Metadata.Value != "" {
+			t.Errorf("%s event carries the secret value %q", e.Type, e.Metadata.Value)
+		}
+		if !e.Metadata.Secret || e.Metadata.ValueSHA256 != want.sha256 {
+			t.Errorf("%s event = %+v, want the secret flag and digest %s", e.Type, *e.Metadata, want.sha256)
+		}
+	}
+
+	// Restores replay the entries as creations, redacted as well
+	if _, err := c.CreateMetadata(ctx, client.CreateMetadataRequest{Path: "app/key", Value: "k3y", Secret: true}); err != nil {
+		t.Fatalf("CreateMetadata: %s", err)
+	}
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot: %s", err)
+	}
+	if err := c.RestoreSnapshot(ctx, *snapshot); err != nil {
+		t.Fatalf("RestoreSnapshot: %s", err)
+	}
+
+	for _, want := range []client.MetadataEventType{client.MetadataEventCreate, client.MetadataEventReset, client.MetadataEventCreate} {
+		var e client.MetadataEvent
+		select {
+		case e = <-watch.Events():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the %s event: %v", want, watch.Err())
+		}
+
+		if e.Type != want {
+			t.Fatalf("event = %+v, want a %s event", e, want)
+		}
+		if e.Metadata != nil && e.Metadata.Value != "" {
+			t.Errorf("%s event carries the secret value %q", e.Type, e.Metadata.Value)
+		}
+	}
+}
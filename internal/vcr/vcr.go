@@ -0,0 +1,338 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package vcr records DirtCloud API traffic to cassette files and replays it, so
+// acceptance tests can run without a server.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EnvMode is the environment variable selecting the Mode.
+const EnvMode = "DIRT_VCR_MODE"
+
+// Mode selects whether traffic is passed through, recorded or replayed.
+type Mode string
+
+const (
+	// ModeDisabled sends requests to the server without recording them.
+	ModeDisabled Mode = ""
+	// ModeRecord sends requests to the server and writes every interaction to the cassette.
+	ModeRecord Mode = "record"
+	// ModeReplay answers requests from the cassette without contacting a server.
+	ModeReplay Mode = "replay"
+)
+
+// ModeFromEnv returns the Mode set in DIRT_VCR_MODE.
+func ModeFromEnv() (Mode, error) {
+	switch mode := Mode(os.Getenv(EnvMode)); mode {
+	case ModeDisabled, "off":
+		return ModeDisabled, nil
+	case ModeRecord, ModeReplay:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, expected record, replay or off", EnvMode, mode)
+	}
+}
+
+// scrubbedValue replaces sensitive header values in cassettes.
+const scrubbedValue = "REDACTED"
+
+// Cassette is the recorded traffic of a single test.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response the server gave to it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL holds only the path and query, so cassettes do not
+// depend on the endpoint they were recorded against. The Authorization header is
+// always scrubbed.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette.
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette file at path. In record mode requests are
+// sent through transport (http.DefaultTransport when nil); in replay mode the cassette
+// must exist.
+func New(mode Mode, path string, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{mode: mode, path: path, transport: transport}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cassette %s does not exist; record it with %s=%s", path, EnvMode, ModeRecord)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.mode {
+	case ModeReplay:
+		return r.replay(req, recorded)
+	case ModeRecord:
+		return r.record(req, recorded)
+	default:
+		return r.transport.RoundTrip(req)
+	}
+}
+
+// record forwards req and appends the interaction to the cassette.
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := resp.Header.Clone()
+	headers.Del("Date")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  recorded,
+		Response: Response{StatusCode: resp.StatusCode, Headers: headers, Body: string(body)},
+	})
+
+	return resp, nil
+}
+
+// replay answers req with the first unused interaction that matches it. Identical
+// requests are answered in the order they were recorded.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no unused interaction in cassette %s matches %s %s %s", r.path, recorded.Method, recorded.URL, recorded.Body)
+}
+
+// Unused returns the interactions of a replayed cassette that no request has used yet.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Save writes the recorded cassette. It does nothing unless recording.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// newRequest captures req for the cassette, leaving its body readable.
+func newRequest(req *http.Request) (Request, error) {
+	recorded := Request{
+		Method:  req.Method,
+		URL:     req.URL.RequestURI(),
+		Headers: req.Header.Clone(),
+	}
+
+	if recorded.Headers.Get("Authorization") != "" {
+		recorded.Headers.Set("Authorization", scrubbedValue)
+	}
+	// The provider version changes between releases but does not affect responses.
+	recorded.Headers.Del("User-Agent")
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return Request{}, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = string(body)
+	}
+
+	return recorded, nil
+}
+
+// matches reports whether a replayed request is the same as the recorded one. JSON
+// bodies are compared semantically so key order does not matter.
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.URL == other.URL && sameBody(r.Body, other.Body)
+}
+
+// sameBody compares two request bodies, as JSON if both parse.
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// CassettePath returns the cassette file for the named test within dir. Subtests get
+// a file in a directory named after their parent test.
+func CassettePath(dir, testName string) string {
+	path := dir
+	for _, part := range strings.Split(testName, "/") {
+		path = filepath.Join(path, unsafeChars.ReplaceAllString(part, "_"))
+	}
+	return path + ".json"
+}
+
+// ForTest returns an HTTP client for t according to DIRT_VCR_MODE, with its cassette
+// stored under dir, or nil when the mode is disabled. Recorded cassettes are written
+// when the test finishes. In replay mode a request missing from the cassette fails t,
+// and so do interactions left unused when the test finishes, unless it was skipped.
+func ForTest(t testing.TB, dir string) *http.Client {
+	t.Helper()
+
+	mode, err := ModeFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode == ModeDisabled {
+		return nil
+	}
+
+	recorder, err := New(mode, CassettePath(dir, t.Name()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := recorder.Save(); err != nil {
+			t.Errorf("saving cassette: %s", err)
+		}
+		if mode != ModeReplay || t.Skipped() || t.Failed() {
+			return
+		}
+		if unused := recorder.Unused(); len(unused) > 0 {
+			t.Errorf("vcr: %d interactions in cassette %s were not replayed, starting with %s %s; record it again with %s=%s",
+				len(unused), recorder.path, unused[0].Request.Method, unused[0].Request.URL, EnvMode, ModeRecord)
+		}
+	})
+
+	return &http.Client{Transport: &failOnUnmatched{t: t, recorder: recorder}}
+}
+
+// failOnUnmatched fails the test when a replayed request is not in the cassette, even if
+// the caller tolerates the resulting error.
+type failOnUnmatched struct {
+	t        testing.TB
+	recorder *Recorder
+}
+
+// RoundTrip implements http.RoundTripper.
+func (f *failOnUnmatched) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := f.recorder.RoundTrip(req)
+	if err != nil && f.recorder.mode == ModeReplay {
+		f.t.Errorf("vcr: %s", err)
+	}
+	return resp, err
+}
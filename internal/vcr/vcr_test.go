@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","body":` + string(body) + `}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := New(ModeRecord, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := do(t, recorder, srv.URL+"/v1/projects", `{"name":"a","cpu":1}`)
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("cassette contains the bearer token:\n%s", data)
+	}
+
+	replayer, err := New(ModeReplay, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	// Key order and endpoint differ from the recording but the request is the same.
+	replayed := do(t, replayer, "http://replay.invalid/v1/projects", `{"cpu":1,"name":"a"}`)
+	if replayed != recorded {
+		t.Errorf("replayed body %q, recorded %q", replayed, recorded)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://replay.invalid/v1/projects", strings.NewReader(`{"name":"a","cpu":1}`))
+	if _, err := replayer.RoundTrip(req); err == nil {
+		t.Error("expected an error for a request with no unused interaction")
+	}
+}
+
+func TestRecorderUnused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := New(ModeRecord, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do(t, recorder, srv.URL+"/v1/projects", `{"name":"a"}`)
+	do(t, recorder, srv.URL+"/v1/projects", `{"name":"b"}`)
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := New(ModeReplay, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unused := replayer.Unused(); len(unused) != 2 {
+		t.Fatalf("%d unused interactions before replaying, want 2", len(unused))
+	}
+
+	do(t, replayer, "http://replay.invalid/v1/projects", `{"name":"a"}`)
+	unused := replayer.Unused()
+	if len(unused) != 1 || unused[0].Request.Body != `{"name":"b"}` {
+		t.Errorf("unused interactions %+v, want only the second request", unused)
+	}
+}
+
+func TestCassettePath(t *testing.T) {
+	got := CassettePath("cassettes", "TestAccThing/with spaces")
+	want := filepath.Join("cassettes", "TestAccThing", "with_spaces.json")
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func do(t *testing.T, rt http.RoundTripper, url, body string) string {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
@@ -8,8 +8,12 @@ package tools
 import (
 	_ "github.com/hashicorp/copywrite"
 	_ "github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs"
+	_ "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen"
 )
 
+// Generate the API models and typed client from the OpenAPI spec.
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config ../internal/api/oapi-codegen.yaml ../api/openapi.yaml
+
 // Generate copyright headers
 //go:generate go run github.com/hashicorp/copywrite headers -d .. --config ../.copywrite.hcl
 